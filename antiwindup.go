@@ -0,0 +1,76 @@
+package pid
+
+import "math"
+
+// AntiWindup selects the strategy a [*Controller] uses to keep the integral
+// term from accumulating while the control signal is saturated by the output
+// limit.
+type AntiWindup int
+
+const (
+	// AntiWindupClamp bounds the integral to the output limit divided by the
+	// integral gain. This is the default strategy. It is a static bound that
+	// ignores the proportional and derivative contributions.
+	AntiWindupClamp AntiWindup = iota
+	// AntiWindupBackCalculation feeds the difference between the saturated and
+	// unsaturated output back into the integrator, scaled by the tracking time
+	// constant (𝑇𝑡), such that the integral tracks the value that keeps the
+	// output at the limit.
+	AntiWindupBackCalculation
+	// AntiWindupConditionalIntegration stops the integrator at the output
+	// limit while the output is saturated and the error pushes further into
+	// saturation.
+	AntiWindupConditionalIntegration
+)
+
+// reconcileIntegral reconciles the integral with the limited control signal
+// according to the configured strategy. The integral has already been
+// advanced for the current step, prevIntegral holds its value before that.
+func (c *Controller) reconcileIntegral(prevIntegral, output, limited, step float64) {
+	if output == limited || c.integralGain == 0.0 {
+		return
+	}
+	switch c.antiWindup {
+	case AntiWindupBackCalculation:
+		// Cap the tracking rate at one: a larger step would correct more than
+		// the excess and cause the integral to oscillate.
+		rate := 1.0
+		if tt := c.trackingTime(); tt > 0.0 {
+			rate = min(step/tt, 1.0)
+		}
+		c.integral += (limited - output) / c.integralGain * rate
+	case AntiWindupConditionalIntegration:
+		// Only stop integrating if the increment of the integral contribution
+		// drives the output further into saturation, otherwise it helps to
+		// leave saturation. The integral advances up to the value that puts
+		// the output at the limit, or not at all if it was already beyond.
+		increment := c.integralGain * (c.integral - prevIntegral)
+		if (output > limited) == (increment > 0.0) {
+			excess := output - limited
+			if math.Abs(excess) < math.Abs(increment) {
+				c.integral -= excess / c.integralGain
+			} else {
+				c.integral = prevIntegral
+			}
+		}
+	}
+}
+
+// trackingTime returns the tracking time constant (𝑇𝑡) for back-calculation.
+// Unless configured, it defaults to the geometric mean of the integral and
+// derivative time constants, or the integral time constant when there is no
+// derivative term.
+func (c *Controller) trackingTime() float64 {
+	if c.trackingTimeConstant > 0.0 {
+		return c.trackingTimeConstant
+	}
+	if c.proportionalGain == 0.0 || c.integralGain == 0.0 {
+		return 0.0
+	}
+	integralTime := math.Abs(c.proportionalGain / c.integralGain)
+	derivativeTime := math.Abs(c.derivativeGain / c.proportionalGain)
+	if derivativeTime == 0.0 {
+		return integralTime
+	}
+	return math.Sqrt(integralTime * derivativeTime)
+}
@@ -0,0 +1,309 @@
+package pid
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+var (
+	// ErrAutotuneIncomplete is returned when the result of an [*Autotuner] is
+	// requested before a stable limit cycle was detected.
+	ErrAutotuneIncomplete = errors.New("autotune: incomplete")
+	// ErrNoLimitCycle is returned when an [*Autotuner] does not detect a stable
+	// limit cycle within the maximum number of cycles or the maximum duration.
+	ErrNoLimitCycle = errors.New("autotune: no stable limit cycle")
+)
+
+// TuningRule derives the controller configuration from the ultimate gain (𝐾𝑢)
+// and the oscillation period (𝑇𝑢) of a process, for example
+// [WithZieglerNicholsMethod] or [WithTyreusLuybenMethod].
+type TuningRule func(ultimateGain, oscillationPeriod float64) Option
+
+// Autotuner identifies the ultimate gain and oscillation period of a process
+// with the relay feedback method by Åström and Hägglund.
+//
+// Instead of a controller, a relay drives the process: the output switches
+// between bias+amplitude and bias-amplitude whenever the error crosses the
+// hysteresis band. For most processes this causes a limit cycle whose period
+// is the oscillation period, and whose amplitude determines the ultimate
+// gain. This avoids manual experiments to bring the process to the stability
+// limit.
+//
+// The Autotuner is driven step by step, calling [Autotuner.Update] in place
+// of [Controller.Update] until [Autotuner.Done] reports true.
+type Autotuner struct {
+	amplitude   float64
+	hysteresis  float64
+	bias        float64
+	cycles      int
+	tolerance   float64
+	maxCycles   int
+	maxDuration float64
+
+	elapsed float64
+	started bool
+	high    bool
+
+	// Time of the last upward switch of the relay, and the measurement extrema
+	// observed since then.
+	lastSwitch     float64
+	hasLastSwitch  bool
+	maxMeasurement float64
+	minMeasurement float64
+
+	periods    []float64
+	amplitudes []float64
+
+	done              bool
+	err               error
+	ultimateGain      float64
+	oscillationPeriod float64
+}
+
+// NewAutotuner constructs a [*Autotuner] configured by the provided options.
+// By default the relay switches between -1 and 1 without hysteresis and the
+// limit cycle is considered stable after three consecutive cycles which
+// deviate by no more than 5%. The experiment fails after 20 cycles or one
+// hour, whichever comes first.
+func NewAutotuner(opts ...AutotunerOption) (*Autotuner, error) {
+	cfg := autotunerOptions{
+		amplitude:   1.0,
+		cycles:      3,
+		tolerance:   0.05,
+		maxCycles:   20,
+		maxDuration: time.Hour,
+	}
+	for _, opt := range opts {
+		if err := opt(&cfg); err != nil {
+			return nil, err
+		}
+	}
+	return &Autotuner{
+		amplitude:   cfg.amplitude,
+		hysteresis:  cfg.hysteresis,
+		bias:        cfg.bias,
+		cycles:      cfg.cycles,
+		tolerance:   cfg.tolerance,
+		maxCycles:   cfg.maxCycles,
+		maxDuration: cfg.maxDuration.Seconds(),
+	}, nil
+}
+
+// Update advances the relay experiment for the given target and current
+// measurement over the provided time step, and returns the control signal to
+// apply to the process. Once the experiment is done, Update returns the bias.
+//
+// An update with a zero or negative time step is rejected with
+// [ErrInvalidTimeStep] and returns the control signal of the previous update.
+func (a *Autotuner) Update(target, current float64, delta time.Duration) (float64, error) {
+	if delta <= 0 {
+		return a.output(), fmt.Errorf("autotune: %w, got: %v", ErrInvalidTimeStep, delta)
+	}
+	if a.done {
+		return a.bias, nil
+	}
+	a.elapsed += delta.Seconds()
+	a.maxMeasurement = max(a.maxMeasurement, current)
+	a.minMeasurement = min(a.minMeasurement, current)
+
+	controlError := target - current
+	switch {
+	case !a.started:
+		a.started = true
+		a.high = controlError >= 0.0
+	case !a.high && controlError > a.hysteresis:
+		a.high = true
+		a.switchUp(current)
+	case a.high && controlError < -a.hysteresis:
+		a.high = false
+	}
+
+	// Without a relay switch, for example because the amplitude cannot move
+	// the process across the target, no cycle ever completes.
+	if !a.done && a.elapsed >= a.maxDuration {
+		a.done = true
+		a.err = fmt.Errorf("%w: exceeded maximum duration of %vs", ErrNoLimitCycle, a.maxDuration)
+	}
+	return a.output(), nil
+}
+
+// output returns the control signal of the relay.
+func (a *Autotuner) output() float64 {
+	switch {
+	case a.done || !a.started:
+		return a.bias
+	case a.high:
+		return a.bias + a.amplitude
+	default:
+		return a.bias - a.amplitude
+	}
+}
+
+// switchUp completes a cycle of the limit cycle on every upward switch of the
+// relay and checks whether the limit cycle is stable.
+func (a *Autotuner) switchUp(current float64) {
+	if a.hasLastSwitch {
+		a.periods = append(a.periods, a.elapsed-a.lastSwitch)
+		a.amplitudes = append(a.amplitudes, (a.maxMeasurement-a.minMeasurement)/2.0)
+	}
+	a.lastSwitch = a.elapsed
+	a.hasLastSwitch = true
+	a.maxMeasurement = current
+	a.minMeasurement = current
+
+	if len(a.periods) < a.cycles {
+		return
+	}
+	periods := a.periods[len(a.periods)-a.cycles:]
+	amplitudes := a.amplitudes[len(a.amplitudes)-a.cycles:]
+	if spread(periods) <= a.tolerance && spread(amplitudes) <= a.tolerance {
+		a.finish(mean(periods), mean(amplitudes))
+		return
+	}
+	if len(a.periods) >= a.maxCycles {
+		a.done = true
+		a.err = ErrNoLimitCycle
+	}
+}
+
+// finish estimates the ultimate gain from the describing function of a relay
+// with hysteresis: 𝐾𝑢 = 4𝑑 / (𝜋·√(𝑎² - 𝜀²)).
+func (a *Autotuner) finish(period, amplitude float64) {
+	a.done = true
+	if amplitude <= a.hysteresis {
+		a.err = fmt.Errorf("%w: amplitude %v does not exceed hysteresis %v", ErrNoLimitCycle, amplitude, a.hysteresis)
+		return
+	}
+	a.oscillationPeriod = period
+	a.ultimateGain = 4.0 * a.amplitude / (math.Pi * math.Sqrt(amplitude*amplitude-a.hysteresis*a.hysteresis))
+}
+
+// Done reports whether the relay experiment has finished, either because a
+// stable limit cycle was detected or because none was found within the
+// maximum number of cycles or the maximum duration.
+func (a *Autotuner) Done() bool {
+	return a.done
+}
+
+// Result returns the estimated ultimate gain (𝐾𝑢) and oscillation period (𝑇𝑢)
+// in seconds.
+func (a *Autotuner) Result() (ultimateGain, oscillationPeriod float64, err error) {
+	if !a.done {
+		return 0.0, 0.0, ErrAutotuneIncomplete
+	}
+	if a.err != nil {
+		return 0.0, 0.0, a.err
+	}
+	return a.ultimateGain, a.oscillationPeriod, nil
+}
+
+// Option returns the controller configuration derived from the result with
+// the given tuning rule, ready to be passed to [New].
+func (a *Autotuner) Option(rule TuningRule) (Option, error) {
+	ultimateGain, oscillationPeriod, err := a.Result()
+	if err != nil {
+		return nil, err
+	}
+	return rule(ultimateGain, oscillationPeriod), nil
+}
+
+// spread returns the range of the values relative to their mean.
+func spread(values []float64) float64 {
+	lo, hi := values[0], values[0]
+	for _, v := range values[1:] {
+		lo = min(lo, v)
+		hi = max(hi, v)
+	}
+	return (hi - lo) / math.Abs(mean(values))
+}
+
+func mean(values []float64) float64 {
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+type autotunerOptions struct {
+	amplitude   float64
+	hysteresis  float64
+	bias        float64
+	cycles      int
+	tolerance   float64
+	maxCycles   int
+	maxDuration time.Duration
+}
+
+// AutotunerOption is a functional option for the configuration of
+// [*Autotuner].
+type AutotunerOption func(*autotunerOptions) error
+
+// WithRelayAmplitude sets the relay amplitude (𝑑), the control signal switches
+// between bias+amplitude and bias-amplitude. A larger amplitude results in a
+// larger limit cycle which is more robust against noise.
+func WithRelayAmplitude(amplitude float64) AutotunerOption {
+	return func(o *autotunerOptions) error {
+		if amplitude <= 0.0 {
+			return fmt.Errorf("autotune: relay amplitude must be positive, got: %v", amplitude)
+		}
+		o.amplitude = amplitude
+		return nil
+	}
+}
+
+// WithRelayHysteresis sets the relay hysteresis (𝜀), the relay only switches
+// when the error leaves the band [-hysteresis, hysteresis]. Hysteresis
+// prevents noise from causing spurious switches.
+func WithRelayHysteresis(hysteresis float64) AutotunerOption {
+	return func(o *autotunerOptions) error {
+		if hysteresis < 0.0 {
+			return fmt.Errorf("autotune: relay hysteresis must not be negative, got: %v", hysteresis)
+		}
+		o.hysteresis = hysteresis
+		return nil
+	}
+}
+
+// WithRelayBias sets the control signal around which the relay switches,
+// typically the control signal that keeps the process near the target.
+func WithRelayBias(bias float64) AutotunerOption {
+	return func(o *autotunerOptions) error {
+		o.bias = bias
+		return nil
+	}
+}
+
+// WithLimitCycleDetection configures when the limit cycle is considered
+// stable: the period and amplitude of the given number of consecutive cycles
+// must not deviate by more than the tolerance relative to their mean. The
+// experiment fails if no stable limit cycle is found within maxCycles.
+func WithLimitCycleDetection(cycles int, tolerance float64, maxCycles int) AutotunerOption {
+	return func(o *autotunerOptions) error {
+		if cycles < 1 || maxCycles < cycles {
+			return fmt.Errorf("autotune: invalid cycles %d and max cycles %d", cycles, maxCycles)
+		}
+		if tolerance < 0.0 {
+			return fmt.Errorf("autotune: tolerance must not be negative, got: %v", tolerance)
+		}
+		o.cycles = cycles
+		o.tolerance = tolerance
+		o.maxCycles = maxCycles
+		return nil
+	}
+}
+
+// WithMaxDuration sets the maximum duration of the relay experiment, after
+// which it fails with [ErrNoLimitCycle]. This bounds the experiment when the
+// relay never switches, for example because the process does not respond.
+func WithMaxDuration(duration time.Duration) AutotunerOption {
+	return func(o *autotunerOptions) error {
+		if duration <= 0 {
+			return fmt.Errorf("autotune: max duration must be positive, got: %v", duration)
+		}
+		o.maxDuration = duration
+		return nil
+	}
+}
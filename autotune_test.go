@@ -0,0 +1,190 @@
+package pid
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+// thirdOrderProcess is the process 1/(s+1)³ with a known ultimate gain of 8
+// and oscillation period of 2π/√3 seconds.
+type thirdOrderProcess struct {
+	x1, x2, x3 float64
+}
+
+func (p *thirdOrderProcess) step(input float64, delta time.Duration) float64 {
+	dt := delta.Seconds()
+	p.x1 += dt * (input - p.x1)
+	p.x2 += dt * (p.x1 - p.x2)
+	p.x3 += dt * (p.x2 - p.x3)
+	return p.x3
+}
+
+func TestAutotuner(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []AutotunerOption
+	}{
+		{
+			name: "ideal-relay",
+		},
+		{
+			name: "relay-with-hysteresis",
+			opts: []AutotunerOption{
+				WithRelayAmplitude(2.0),
+				WithRelayHysteresis(0.01),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tuner, err := NewAutotuner(tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, _, err := tuner.Result(); !errors.Is(err, ErrAutotuneIncomplete) {
+				t.Fatalf("got %v, want: %v", err, ErrAutotuneIncomplete)
+			}
+
+			const delta = 10 * time.Millisecond
+			var (
+				process  thirdOrderProcess
+				current  float64
+				duration time.Duration
+			)
+			for !tuner.Done() {
+				if duration > 10*time.Minute {
+					t.Fatal("autotune did not finish")
+				}
+				control, err := tuner.Update(0.0, current, delta)
+				if err != nil {
+					t.Fatal(err)
+				}
+				current = process.step(control, delta)
+				duration += delta
+			}
+
+			ultimateGain, oscillationPeriod, err := tuner.Result()
+			if err != nil {
+				t.Fatal(err)
+			}
+			// The relay method approximates the process by its first harmonic,
+			// allow for the resulting estimation error.
+			if want := 8.0; math.Abs(ultimateGain-want)/want > 0.15 {
+				t.Errorf("got ultimate gain %v, want: %v", ultimateGain, want)
+			}
+			if want := 2 * math.Pi / math.Sqrt(3); math.Abs(oscillationPeriod-want)/want > 0.05 {
+				t.Errorf("got oscillation period %v, want: %v", oscillationPeriod, want)
+			}
+
+			opt, err := tuner.Option(WithTyreusLuybenMethod)
+			if err != nil {
+				t.Fatal(err)
+			}
+			pid, err := New(opt)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, want := pid.proportionalGain, ultimateGain/2.2; got != want {
+				t.Errorf("got proportional gain %v, want: %v", got, want)
+			}
+		})
+	}
+}
+
+func TestNewAutotuner_InvalidOptions(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []AutotunerOption
+	}{
+		{
+			name: "non-positive-amplitude",
+			opts: []AutotunerOption{WithRelayAmplitude(0)},
+		},
+		{
+			name: "negative-hysteresis",
+			opts: []AutotunerOption{WithRelayHysteresis(-1)},
+		},
+		{
+			name: "max-cycles-below-cycles",
+			opts: []AutotunerOption{WithLimitCycleDetection(3, 0.05, 2)},
+		},
+		{
+			name: "non-positive-max-duration",
+			opts: []AutotunerOption{WithMaxDuration(0)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewAutotuner(tt.opts...); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
+
+func TestAutotuner_NoLimitCycle(t *testing.T) {
+	tuner, err := NewAutotuner(WithLimitCycleDetection(3, 0.0, 5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Varying time steps and measurements prevent the cycles from matching.
+	var current float64
+	for i := 0; !tuner.Done(); i++ {
+		if i > 1000 {
+			t.Fatal("autotune did not finish")
+		}
+		control, err := tuner.Update(0.0, current, time.Duration(1+i%3)*time.Second)
+		if err != nil {
+			t.Fatal(err)
+		}
+		current = control * float64(1+i%2)
+	}
+	if _, _, err := tuner.Result(); !errors.Is(err, ErrNoLimitCycle) {
+		t.Fatalf("got %v, want: %v", err, ErrNoLimitCycle)
+	}
+}
+
+func TestAutotuner_NoRelaySwitch(t *testing.T) {
+	tuner, err := NewAutotuner(WithMaxDuration(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The process does not respond, so the relay never switches.
+	for i := 0; !tuner.Done(); i++ {
+		if i > 60 {
+			t.Fatal("autotune did not finish")
+		}
+		if _, err := tuner.Update(1.0, 0.0, time.Second); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, _, err := tuner.Result(); !errors.Is(err, ErrNoLimitCycle) {
+		t.Fatalf("got %v, want: %v", err, ErrNoLimitCycle)
+	}
+}
+
+func TestAutotuner_InvalidTimeStep(t *testing.T) {
+	tuner, err := NewAutotuner(WithRelayBias(5.0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tuner.Update(1.0, 0.0, time.Second); err != nil {
+		t.Fatal(err)
+	}
+	for _, delta := range []time.Duration{0, -time.Second} {
+		control, err := tuner.Update(-1.0, 0.0, delta)
+		if !errors.Is(err, ErrInvalidTimeStep) {
+			t.Fatalf("got %v, want: %v", err, ErrInvalidTimeStep)
+		}
+		if want := 6.0; control != want {
+			t.Errorf("got %v, want: %v", control, want)
+		}
+	}
+	if tuner.elapsed != 1.0 {
+		t.Errorf("got elapsed %v, want: %v", tuner.elapsed, 1.0)
+	}
+}
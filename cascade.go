@@ -0,0 +1,123 @@
+package pid
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Cascade chains two controllers: the control signal of the outer controller
+// is the target of the inner controller. For example, an outer loop regulates
+// the queue depth by setting the target concurrency, which an inner loop
+// regulates by setting the admission rate. The inner loop corrects
+// disturbances before they affect the outer loop.
+//
+// When the inner controller saturates at its output limit, it can no longer
+// follow a target that pushes further into saturation. The integral of the
+// outer controller is then frozen to prevent windup. This assumes that both
+// controllers are direct acting, that is, a larger control signal increases
+// the measurement.
+type Cascade struct {
+	outer *Controller
+	inner *Controller
+
+	outerInterval time.Duration
+	elapsed       time.Duration
+	started       bool
+	innerTarget   float64
+
+	// innerSaturation is 1 when the inner control signal is at its upper
+	// limit, -1 when it is at its lower limit, and 0 otherwise.
+	innerSaturation int
+}
+
+// NewCascade constructs a [*Cascade] from the outer and inner controllers,
+// configured by the provided options.
+func NewCascade(outer, inner *Controller, opts ...CascadeOption) (*Cascade, error) {
+	c := &Cascade{
+		outer: outer,
+		inner: inner,
+	}
+	for _, opt := range opts {
+		if err := opt(c); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// Update computes and returns the next control signal of the inner controller
+// for the given target of the outer controller and the current measurements
+// of both loops over the provided time step. The outer controller is updated
+// at its own interval, if configured, otherwise at every update.
+func (c *Cascade) Update(target, outerCurrent, innerCurrent float64, delta time.Duration) float64 {
+	c.elapsed += delta
+	if !c.started || c.elapsed >= c.outerInterval {
+		c.started = true
+		// Block the integration of the outer controller if it pushes the
+		// inner controller further into saturation. The outer controller
+		// applies the block itself, such that its control signal, rate limit
+		// and metrics remain consistent, and ignores it in manual mode.
+		c.outer.blockedIntegration = c.innerSaturation
+		c.innerTarget = c.outer.Update(target, outerCurrent, c.elapsed)
+		c.outer.blockedIntegration = 0
+		c.elapsed = 0
+	}
+
+	controlSignal := c.inner.Update(c.innerTarget, innerCurrent, delta)
+	switch controlSignal {
+	case c.inner.outputLimit.upper:
+		c.innerSaturation = 1
+	case c.inner.outputLimit.lower:
+		c.innerSaturation = -1
+	default:
+		c.innerSaturation = 0
+	}
+	return controlSignal
+}
+
+// Outer returns the outer [*Controller].
+func (c *Cascade) Outer() *Controller {
+	return c.outer
+}
+
+// Inner returns the inner [*Controller].
+func (c *Cascade) Inner() *Controller {
+	return c.inner
+}
+
+// Close closes both controllers, see [Controller.Close].
+func (c *Cascade) Close() error {
+	return errors.Join(c.outer.Close(), c.inner.Close())
+}
+
+// CascadeOption is a functional option for the configuration of [*Cascade].
+type CascadeOption func(*Cascade) error
+
+// WithOuterInterval sets the interval at which the outer controller is
+// updated. The outer loop is typically slower than the inner loop, updating
+// it less frequently reduces noise on the inner target. By default, the outer
+// controller is updated on every update.
+func WithOuterInterval(interval time.Duration) CascadeOption {
+	return func(c *Cascade) error {
+		if interval < 0 {
+			return fmt.Errorf("cascade: outer interval must not be negative, got: %v", interval)
+		}
+		c.outerInterval = interval
+		return nil
+	}
+}
+
+// WithCascadePrometheusMetrics enables Prometheus instrumentation for both
+// controllers, see [WithPrometheusMetrics]. The controllers are labeled with
+// the given name followed by "/outer" and "/inner" respectively.
+func WithCascadePrometheusMetrics(name string, registerer prometheus.Registerer, opts ...MetricsOption) CascadeOption {
+	return func(c *Cascade) error {
+		if err := c.outer.Reconfigure(WithPrometheusMetrics(name+"/outer", registerer, opts...)); err != nil {
+			return err
+		}
+		return c.inner.Reconfigure(WithPrometheusMetrics(name+"/inner", registerer, opts...))
+	}
+}
@@ -0,0 +1,188 @@
+package pid
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// cascadeProcess is a process for a cascade: the inner measurement follows the
+// control signal as a first-order lag, and the outer measurement integrates
+// the inner measurement less a constant drain.
+type cascadeProcess struct {
+	inner float64
+	outer float64
+}
+
+func (p *cascadeProcess) step(controlSignal float64, delta time.Duration) {
+	dt := delta.Seconds()
+	p.inner += dt * (controlSignal - p.inner)
+	p.outer += dt * (p.inner - 1.0)
+}
+
+func TestCascade(t *testing.T) {
+	outer, err := New(
+		WithProportionalGain(1.0),
+		WithIntegralGain(0.1),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	inner, err := New(
+		WithProportionalGain(2.0),
+		WithIntegralGain(2.0),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cascade, err := NewCascade(outer, inner, WithOuterInterval(500*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const delta = 100 * time.Millisecond
+	var process cascadeProcess
+	for range 1000 {
+		controlSignal := cascade.Update(10, process.outer, process.inner, delta)
+		process.step(controlSignal, delta)
+	}
+	if got, want := process.outer, 10.0; math.Abs(got-want) > 0.01 {
+		t.Errorf("got outer %v, want: %v", got, want)
+	}
+	// The drain is balanced once the inner measurement equals the drain.
+	if got, want := process.inner, 1.0; math.Abs(got-want) > 0.01 {
+		t.Errorf("got inner %v, want: %v", got, want)
+	}
+}
+
+func TestCascade_OuterInterval(t *testing.T) {
+	outer, err := New(WithIntegralGain(1.0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	inner, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cascade, err := NewCascade(outer, inner, WithOuterInterval(300*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The outer controller is updated on the first update and then once the
+	// interval elapsed, integrating the error over the elapsed time.
+	for range 4 {
+		cascade.Update(1, 0, 0, 100*time.Millisecond)
+	}
+	if got, want := outer.integral, 0.1+0.3; math.Abs(got-want) > 1e-9 {
+		t.Errorf("got integral %v, want: %v", got, want)
+	}
+}
+
+func TestCascade_InnerSaturationFreezesOuterIntegral(t *testing.T) {
+	outer, err := New(
+		WithProportionalGain(1.0),
+		WithIntegralGain(1.0),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	inner, err := New(
+		WithProportionalGain(1.0),
+		WithOutputLimit(-1, 1),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cascade, err := NewCascade(outer, inner)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The inner controller saturates immediately, the outer integral must not
+	// accumulate beyond the first update while the error persists.
+	for range 10 {
+		if got, want := cascade.Update(10, 0, 0, 1*time.Second), 1.0; got != want {
+			t.Fatalf("got %v, want: %v", got, want)
+		}
+	}
+	if got, want := outer.integral, 10.0; got != want {
+		t.Errorf("got integral %v, want: %v", got, want)
+	}
+	if got, want := outer.prevOutput, cascade.innerTarget; got != want {
+		t.Errorf("got outer output %v, want: %v", got, want)
+	}
+
+	// Once the error reverses, the outer integral resumes.
+	cascade.Update(10, 20, 0, 1*time.Second)
+	if got, want := outer.integral, 0.0; got != want {
+		t.Errorf("got integral %v, want: %v", got, want)
+	}
+}
+
+func TestCascade_OuterManualMode(t *testing.T) {
+	outer, err := New(
+		WithProportionalGain(1.0),
+		WithIntegralGain(1.0),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	inner, err := New(
+		WithProportionalGain(1.0),
+		WithOutputLimit(-1, 1),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cascade, err := NewCascade(outer, inner)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The inner target is set by the operator while the inner controller
+	// saturates, it must not be altered by the saturation handling.
+	outer.SetManual(3)
+	for range 3 {
+		cascade.Update(10, 0, 0, 1*time.Second)
+		if got, want := cascade.innerTarget, 3.0; got != want {
+			t.Fatalf("got inner target %v, want: %v", got, want)
+		}
+	}
+}
+
+func TestCascade_PrometheusMetrics(t *testing.T) {
+	outer, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	inner, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	registry := prometheus.NewRegistry()
+	cascade, err := NewCascade(outer, inner, WithCascadePrometheusMetrics("queue", registry))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cascade.Update(10, 5, 2, 1*time.Second)
+
+	checkLabelValue(t, registry, "pid_control_signal", nameLabel, "queue/outer")
+	checkLabelValue(t, registry, "pid_control_signal", nameLabel, "queue/inner")
+}
+
+func TestNewCascade_InvalidOptions(t *testing.T) {
+	outer, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	inner, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewCascade(outer, inner, WithOuterInterval(-1)); err == nil {
+		t.Fatal("expected error")
+	}
+}
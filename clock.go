@@ -0,0 +1,76 @@
+package pid
+
+import (
+	"errors"
+	"time"
+)
+
+// Clock provides the current time to [Controller.UpdateNow]. Inject a fake
+// implementation with [WithClock] to control the time in tests.
+type Clock interface {
+	Now() time.Time
+}
+
+// systemClock is the [Clock] backed by [time.Now].
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// UpdateAt is like [Controller.Update] but computes the time step from the
+// time of the previous call to UpdateAt or [Controller.UpdateNow], instead of
+// relying on the caller to track it.
+//
+// The first call only establishes the time reference: the error is neither
+// integrated nor differentiated and the previous control signal is returned,
+// which is zero for a fresh controller, clamped to the output limit.
+// This avoids integrating over the time since an arbitrary point in the past,
+// such as the process start. If the time goes backwards, the update is
+// rejected and the time step of the next update is measured from the new time.
+func (c *Controller) UpdateAt(target, current float64, now time.Time) float64 {
+	if c.lastUpdate.IsZero() {
+		c.lastUpdate = now
+		return c.seed(target, current)
+	}
+	delta := now.Sub(c.lastUpdate)
+	c.lastUpdate = now
+	return c.Update(target, current, delta)
+}
+
+// UpdateNow calls [Controller.UpdateAt] with the current time of the clock
+// configured with [WithClock].
+func (c *Controller) UpdateNow(target, current float64) float64 {
+	return c.UpdateAt(target, current, c.clock.Now())
+}
+
+// seed initializes the previous error, target, and measurement from the first
+// sample, such that the derivative of the next update does not spike.
+func (c *Controller) seed(target, current float64) float64 {
+	if validateUpdate(target, current, 0.0, time.Nanosecond) == nil {
+		controlError := target - current
+		if c.errorShaping != nil {
+			controlError = c.errorShaping.apply(controlError)
+		}
+		c.prevControlError = controlError
+		c.prevTarget = target
+		c.prevMeasurement = current
+		c.measured = true
+	}
+	if c.manual {
+		return c.manualOutput
+	}
+	return c.outputLimit.apply(c.prevOutput)
+}
+
+// WithClock sets the [Clock] used by [Controller.UpdateNow]. By default, the
+// system clock is used.
+func WithClock(clock Clock) Option {
+	return func(o *options) error {
+		if clock == nil {
+			return errors.New("clock: must not be nil")
+		}
+		o.clock = clock
+		return nil
+	}
+}
@@ -0,0 +1,118 @@
+package pid
+
+import (
+	"testing"
+	"time"
+)
+
+// fakeClock is a [Clock] that only advances when told to.
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func TestController_UpdateNow(t *testing.T) {
+	opts := []Option{
+		WithProportionalGain(1.0),
+		WithIntegralGain(0.5),
+		WithDerivativeGain(0.25),
+	}
+	clock := &fakeClock{now: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}
+	pid, err := New(append(opts, WithClock(clock))...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reference, err := New(opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The first update only establishes the time reference.
+	if got := pid.UpdateNow(10, 7); got != 0.0 {
+		t.Errorf("got %v, want: 0", got)
+	}
+	// Seed the reference controller with the first sample.
+	reference.prevControlError = 3
+	reference.prevTarget = 10
+	reference.prevMeasurement = 7
+
+	for _, step := range []struct {
+		current float64
+		delta   time.Duration
+	}{
+		{current: 8, delta: 1 * time.Second},
+		{current: 9, delta: 500 * time.Millisecond},
+		{current: 9.5, delta: 2 * time.Second},
+	} {
+		clock.advance(step.delta)
+		if got, want := pid.UpdateNow(10, step.current), reference.Update(10, step.current, step.delta); got != want {
+			t.Errorf("got %v, want: %v", got, want)
+		}
+	}
+}
+
+func TestController_UpdateAtFirstSample(t *testing.T) {
+	pid, err := New(
+		WithProportionalGain(0.0),
+		WithIntegralGain(1.0),
+		WithDerivativeGain(1.0),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A first update long after an arbitrary start must neither integrate
+	// over the elapsed time nor cause a derivative kick afterwards.
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	pid.UpdateAt(10, 7, start)
+	if got, want := pid.UpdateAt(10, 7, start.Add(1*time.Second)), 3.0; got != want {
+		t.Errorf("got %v, want: %v", got, want)
+	}
+
+	pid.Reset()
+	if !pid.lastUpdate.IsZero() {
+		t.Errorf("got last update %v, want zero time", pid.lastUpdate)
+	}
+}
+
+func TestController_UpdateAtFirstSampleWithinOutputLimit(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}
+	pid, err := New(WithOutputLimit(5, 500), WithClock(clock))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := pid.UpdateNow(100, 50), 5.0; got != want {
+		t.Errorf("got %v, want: %v", got, want)
+	}
+}
+
+func TestController_UpdateAtBackwards(t *testing.T) {
+	pid, err := New(WithProportionalGain(0.0), WithIntegralGain(1.0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	pid.UpdateAt(10, 7, start)
+	want := pid.UpdateAt(10, 7, start.Add(1*time.Second))
+
+	// The update going back in time is rejected, the next one continues from
+	// the new time.
+	if got := pid.UpdateAt(10, 7, start); got != want {
+		t.Errorf("got %v, want: %v", got, want)
+	}
+	if got, want := pid.UpdateAt(10, 7, start.Add(1*time.Second)), want+3.0; got != want {
+		t.Errorf("got %v, want: %v", got, want)
+	}
+}
+
+func TestWithClock_Nil(t *testing.T) {
+	if _, err := New(WithClock(nil)); err == nil {
+		t.Fatal("expected error")
+	}
+}
@@ -17,7 +17,7 @@ func ExampleNew() {
 	}
 
 	fmt.Printf("%#v\n", controller)
-	// Output: &pid.Controller{proportionalGain:2, integralGain:2, derivativeGain:0.5, prevControlError:0, integral:0, derivative:0, outputLimit:pid.limit{lower:-Inf, upper:+Inf}, integralLimit:pid.limit{lower:-Inf, upper:+Inf}, lowPassFilterError:0.00390625, lowPassFilterDerivative:0.03125, trapezoidalIntegral:false, metrics:(*pid.metrics)(nil)}
+	// Output: &pid.Controller{proportionalGain:2, integralGain:2, derivativeGain:0.5, proportionalWeight:1, derivativeWeight:1, errorShaping:(*pid.errorShaping)(nil), prevControlError:0, prevMeasurement:0, prevTarget:0, integral:0, derivative:0, measured:false, outputLimit:pid.limit{lower:-Inf, upper:+Inf}, integralLimit:pid.limit{lower:-Inf, upper:+Inf}, outputRateLimit:(*pid.limit)(nil), prevOutput:0, antiWindup:0, trackingTimeConstant:0, blockedIntegration:0, lowPassFilterError:0.00390625, lowPassFilterDerivative:0.03125, trapezoidalIntegral:false, derivativeOnMeasurement:false, maxTimeStep:0, clock:pid.systemClock{}, lastUpdate:time.Time{wall:0x0, ext:0, loc:(*time.Location)(nil)}, gainSchedule:(*pid.gainSchedule)(nil), schedulingSignal:0, manual:false, manualOutput:0, transfer:false, metrics:pid.recorder(nil)}
 }
 
 func ExampleController_Update() {
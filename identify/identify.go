@@ -0,0 +1,201 @@
+// Package identify estimates process models from recorded time series of the
+// control signal and the measurement, such as the values exported by the
+// pid_control_signal and pid_current metrics. The estimated models feed into
+// the model-based tuning rules of package pid, for example
+// [pid.WithSIMCMethod].
+package identify
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/konradreiche/pid"
+)
+
+var (
+	// ErrInsufficientSamples is returned when there are too few samples to
+	// estimate a model.
+	ErrInsufficientSamples = errors.New("identify: insufficient samples")
+	// ErrNoStep is returned by step response methods when the control signal
+	// does not change.
+	ErrNoStep = errors.New("identify: no step in control signal")
+	// ErrNoResponse is returned when the measurement does not respond to the
+	// control signal.
+	ErrNoResponse = errors.New("identify: no response in measurement")
+)
+
+// Sample is a recorded control signal and the measurement at the same time.
+type Sample struct {
+	// Time since the start of the recording.
+	Time time.Duration
+	// ControlSignal is the output of the controller applied to the process.
+	ControlSignal float64
+	// Current is the measurement of the process.
+	Current float64
+}
+
+// SecondOrder is a second-order-plus-dead-time model of a process, described
+// by two time constants in series.
+type SecondOrder struct {
+	// Gain (𝐾) is the steady-state change of the measurement per unit change
+	// of the control signal.
+	Gain float64
+	// TimeConstant1 (𝑇₁) in seconds is the dominant time constant.
+	TimeConstant1 float64
+	// TimeConstant2 (𝑇₂) in seconds is the smaller time constant.
+	TimeConstant2 float64
+	// DeadTime (𝐿) in seconds is the delay before the measurement responds to
+	// a change of the control signal.
+	DeadTime float64
+}
+
+// FOPDT approximates the model by a first-order-plus-dead-time model using
+// Skogestad's half rule: half of the smaller time constant is added to the
+// dominant time constant, the other half to the dead time.
+func (m SecondOrder) FOPDT() pid.FOPDT {
+	return pid.FOPDT{
+		Gain:         m.Gain,
+		TimeConstant: m.TimeConstant1 + m.TimeConstant2/2.0,
+		DeadTime:     m.DeadTime + m.TimeConstant2/2.0,
+	}
+}
+
+// stepResponse is the response of the measurement to a single step in the
+// control signal, normalized to the range [0, 1].
+type stepResponse struct {
+	gain     float64
+	times    []float64
+	response []float64
+}
+
+// newStepResponse extracts the step response from the samples. The process
+// must be at steady state before the step, and settled by the last sample.
+func newStepResponse(samples []Sample) (*stepResponse, error) {
+	if len(samples) < 3 {
+		return nil, fmt.Errorf("%w: got %d, want at least: 3", ErrInsufficientSamples, len(samples))
+	}
+	first, last := samples[0], samples[len(samples)-1]
+	step := -1
+	for i, s := range samples {
+		if s.ControlSignal != first.ControlSignal {
+			step = i
+			break
+		}
+	}
+	if step == -1 {
+		return nil, ErrNoStep
+	}
+	change := last.Current - first.Current
+	if change == 0.0 {
+		return nil, ErrNoResponse
+	}
+
+	r := &stepResponse{
+		gain: change / (last.ControlSignal - first.ControlSignal),
+	}
+	start := samples[step].Time
+	for _, s := range samples[step:] {
+		r.times = append(r.times, (s.Time - start).Seconds())
+		r.response = append(r.response, (s.Current-first.Current)/change)
+	}
+	return r, nil
+}
+
+// crossing returns the time at which the normalized response first reaches
+// the given fraction, interpolated linearly between samples.
+func (r *stepResponse) crossing(fraction float64) (float64, error) {
+	for i := 1; i < len(r.response); i++ {
+		if r.response[i] < fraction {
+			continue
+		}
+		t0, t1 := r.times[i-1], r.times[i]
+		y0, y1 := r.response[i-1], r.response[i]
+		return t0 + (fraction-y0)*(t1-t0)/(y1-y0), nil
+	}
+	return 0.0, fmt.Errorf("%w: response does not reach %v%%", ErrNoResponse, 100*fraction)
+}
+
+// Tangent estimates a first-order-plus-dead-time model from a step response
+// using the tangent method by Ziegler and Nichols. The tangent at the point of
+// maximum slope intersects the initial value after the dead time, and the
+// final value after the dead time plus the time constant.
+//
+// The method is simple but sensitive to noise since it relies on a single
+// point of the response.
+func Tangent(samples []Sample) (pid.FOPDT, error) {
+	r, err := newStepResponse(samples)
+	if err != nil {
+		return pid.FOPDT{}, err
+	}
+	var slope, t, y float64
+	for i := 1; i < len(r.response); i++ {
+		dt := r.times[i] - r.times[i-1]
+		if dt <= 0.0 {
+			continue
+		}
+		if s := (r.response[i] - r.response[i-1]) / dt; s > slope {
+			slope = s
+			t = (r.times[i] + r.times[i-1]) / 2.0
+			y = (r.response[i] + r.response[i-1]) / 2.0
+		}
+	}
+	if slope == 0.0 {
+		return pid.FOPDT{}, ErrNoResponse
+	}
+	return pid.FOPDT{
+		Gain:         r.gain,
+		TimeConstant: 1.0 / slope,
+		DeadTime:     max(t-y/slope, 0.0),
+	}, nil
+}
+
+// Smith estimates a first-order-plus-dead-time model from a step response
+// using Smith's two-point method, based on the times at which the response
+// reaches 28.3% and 63.2% of its final value.
+func Smith(samples []Sample) (pid.FOPDT, error) {
+	return twoPoint(samples, 0.283, 0.632, func(t1, t2 float64) (float64, float64) {
+		timeConstant := 1.5 * (t2 - t1)
+		return timeConstant, t2 - timeConstant
+	})
+}
+
+// Sundaresan estimates a first-order-plus-dead-time model from a step response
+// using the two-point method by Sundaresan and Krishnaswamy, based on the
+// times at which the response reaches 35.3% and 85.3% of its final value.
+func Sundaresan(samples []Sample) (pid.FOPDT, error) {
+	return twoPoint(samples, 0.353, 0.853, func(t1, t2 float64) (float64, float64) {
+		return 0.67 * (t2 - t1), 1.3*t1 - 0.29*t2
+	})
+}
+
+// twoPoint estimates a first-order-plus-dead-time model from the times at
+// which the response reaches two fractions of its final value.
+func twoPoint(
+	samples []Sample,
+	fraction1, fraction2 float64,
+	estimate func(t1, t2 float64) (timeConstant, deadTime float64),
+) (pid.FOPDT, error) {
+	r, err := newStepResponse(samples)
+	if err != nil {
+		return pid.FOPDT{}, err
+	}
+	t1, err := r.crossing(fraction1)
+	if err != nil {
+		return pid.FOPDT{}, err
+	}
+	t2, err := r.crossing(fraction2)
+	if err != nil {
+		return pid.FOPDT{}, err
+	}
+	timeConstant, deadTime := estimate(t1, t2)
+	if timeConstant <= 0.0 || math.IsNaN(timeConstant) {
+		return pid.FOPDT{}, ErrNoResponse
+	}
+	return pid.FOPDT{
+		Gain:         r.gain,
+		TimeConstant: timeConstant,
+		DeadTime:     max(deadTime, 0.0),
+	}, nil
+}
@@ -0,0 +1,306 @@
+package identify
+
+import (
+	"errors"
+	"math"
+	"math/rand/v2"
+	"testing"
+	"time"
+
+	"github.com/konradreiche/pid"
+)
+
+// fopdtStepResponse samples the exact response of a first-order-plus-dead-time
+// process to a unit step of the control signal at one second.
+func fopdtStepResponse(model pid.FOPDT, interval, duration time.Duration) []Sample {
+	const (
+		initialControlSignal = 0.5
+		initialCurrent       = 3.0
+		stepTime             = 1.0
+	)
+	var samples []Sample
+	for t := time.Duration(0); t <= duration; t += interval {
+		s := Sample{
+			Time:          t,
+			ControlSignal: initialControlSignal,
+			Current:       initialCurrent,
+		}
+		if t.Seconds() >= stepTime {
+			s.ControlSignal += 1.0
+		}
+		if elapsed := t.Seconds() - stepTime - model.DeadTime; elapsed > 0.0 {
+			s.Current += model.Gain * (1.0 - math.Exp(-elapsed/model.TimeConstant))
+		}
+		samples = append(samples, s)
+	}
+	return samples
+}
+
+// secondOrderStepResponse simulates the response of a second-order-plus-dead-
+// time process to a unit step of the control signal at one second.
+func secondOrderStepResponse(model SecondOrder, interval, duration time.Duration) []Sample {
+	const (
+		stepTime = 1.0
+		substeps = 100
+	)
+	var (
+		samples []Sample
+		x1, x2  float64
+		input   []float64
+	)
+	dt := interval.Seconds() / substeps
+	delay := int(math.Round(model.DeadTime / dt))
+	for t := time.Duration(0); t <= duration; t += interval {
+		var u float64
+		if t.Seconds() >= stepTime {
+			u = 1.0
+		}
+		samples = append(samples, Sample{Time: t, ControlSignal: u, Current: x2})
+		for range substeps {
+			input = append(input, u)
+			var delayed float64
+			if i := len(input) - 1 - delay; i >= 0 {
+				delayed = input[i]
+			}
+			x1 += dt * (model.Gain*delayed - x1) / model.TimeConstant1
+			x2 += dt * (x1 - x2) / model.TimeConstant2
+		}
+	}
+	return samples
+}
+
+func TestFOPDTMethods(t *testing.T) {
+	want := pid.FOPDT{Gain: 2, TimeConstant: 5, DeadTime: 1}
+	samples := fopdtStepResponse(want, 100*time.Millisecond, 60*time.Second)
+
+	tests := []struct {
+		name      string
+		method    func([]Sample) (pid.FOPDT, error)
+		tolerance float64
+	}{
+		{
+			name:      "tangent",
+			method:    Tangent,
+			tolerance: 0.05,
+		},
+		{
+			name:      "smith",
+			method:    Smith,
+			tolerance: 0.01,
+		},
+		{
+			name:      "sundaresan",
+			method:    Sundaresan,
+			tolerance: 0.05,
+		},
+		{
+			name:      "least-squares",
+			method:    LeastSquares,
+			tolerance: 1e-6,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.method(samples)
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkModel(t, got, want, tt.tolerance)
+		})
+	}
+}
+
+func TestLeastSquares_ArbitraryInput(t *testing.T) {
+	want := pid.FOPDT{Gain: -1.5, TimeConstant: 3, DeadTime: 0.5}
+
+	// Simulate the exact discretization of the process under a control signal
+	// that changes every few samples, as during regular operation.
+	const interval = 100 * time.Millisecond
+	a := math.Exp(-interval.Seconds() / want.TimeConstant)
+	delay := int(want.DeadTime / interval.Seconds())
+	samples := make([]Sample, 500)
+	for k := range samples {
+		samples[k].Time = time.Duration(k) * interval
+		samples[k].ControlSignal = math.Sin(float64(k / 20))
+		if k == 0 {
+			continue
+		}
+		var delayed float64
+		if i := k - 1 - delay; i >= 0 {
+			delayed = samples[i].ControlSignal
+		}
+		samples[k].Current = a*samples[k-1].Current + want.Gain*(1-a)*delayed
+	}
+
+	got, err := LeastSquares(samples)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkModel(t, got, want, 1e-6)
+}
+
+func TestLeastSquares_MeasurementNoise(t *testing.T) {
+	tests := []struct {
+		name      string
+		want      pid.FOPDT
+		interval  time.Duration
+		duration  time.Duration
+		noise     float64
+		tolerance float64
+	}{
+		{
+			name:      "slow-sampling",
+			want:      pid.FOPDT{Gain: 2, TimeConstant: 10, DeadTime: 3},
+			interval:  500 * time.Millisecond,
+			duration:  100 * time.Second,
+			noise:     0.01,
+			tolerance: 0.05,
+		},
+		{
+			name:      "fast-sampling",
+			want:      pid.FOPDT{Gain: 2, TimeConstant: 5, DeadTime: 1},
+			interval:  250 * time.Millisecond,
+			duration:  60 * time.Second,
+			noise:     0.01,
+			tolerance: 0.05,
+		},
+		{
+			name:      "negative-gain",
+			want:      pid.FOPDT{Gain: -1.5, TimeConstant: 3, DeadTime: 0.5},
+			interval:  100 * time.Millisecond,
+			duration:  30 * time.Second,
+			noise:     0.01,
+			tolerance: 0.05,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			samples := withNoise(fopdtStepResponse(tt.want, tt.interval, tt.duration), tt.noise)
+			got, err := LeastSquares(samples)
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkModel(t, got, tt.want, tt.tolerance)
+		})
+	}
+}
+
+func TestLeastSquaresSecondOrder(t *testing.T) {
+	want := SecondOrder{Gain: 2, TimeConstant1: 4, TimeConstant2: 1, DeadTime: 0.5}
+	samples := secondOrderStepResponse(want, 50*time.Millisecond, 40*time.Second)
+
+	got, err := LeastSquaresSecondOrder(samples)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const tolerance = 0.05
+	for _, c := range []struct {
+		name      string
+		got, want float64
+	}{
+		{"gain", got.Gain, want.Gain},
+		{"time constant 1", got.TimeConstant1, want.TimeConstant1},
+		{"time constant 2", got.TimeConstant2, want.TimeConstant2},
+	} {
+		if math.Abs(c.got-c.want)/c.want > tolerance {
+			t.Errorf("got %s %v, want: %v", c.name, c.got, c.want)
+		}
+	}
+	// Dead time is only identified up to the sampling interval.
+	if math.Abs(got.DeadTime-want.DeadTime) > 0.1 {
+		t.Errorf("got dead time %v, want: %v", got.DeadTime, want.DeadTime)
+	}
+
+	// The approximated model must feed into the tuning rules.
+	if _, err := pid.New(pid.WithSIMCMethod(got.FOPDT(), got.FOPDT().DeadTime)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSecondOrder_FOPDT(t *testing.T) {
+	got := SecondOrder{Gain: 2, TimeConstant1: 4, TimeConstant2: 1, DeadTime: 0.5}.FOPDT()
+	want := pid.FOPDT{Gain: 2, TimeConstant: 4.5, DeadTime: 1}
+	if got != want {
+		t.Errorf("got %v, want: %v", got, want)
+	}
+}
+
+func TestMethods_InvalidSamples(t *testing.T) {
+	constant := []Sample{
+		{Time: 0, ControlSignal: 1, Current: 1},
+		{Time: time.Second, ControlSignal: 1, Current: 1},
+		{Time: 2 * time.Second, ControlSignal: 1, Current: 1},
+	}
+	noResponse := []Sample{
+		{Time: 0, ControlSignal: 1, Current: 1},
+		{Time: time.Second, ControlSignal: 2, Current: 1},
+		{Time: 2 * time.Second, ControlSignal: 2, Current: 1},
+	}
+	irregular := fopdtStepResponse(pid.FOPDT{Gain: 1, TimeConstant: 1}, time.Second, 20*time.Second)
+	irregular[5].Time += 500 * time.Millisecond
+
+	tests := []struct {
+		name    string
+		method  func([]Sample) (pid.FOPDT, error)
+		samples []Sample
+		wantErr error
+	}{
+		{
+			name:    "insufficient-samples",
+			method:  Smith,
+			samples: constant[:2],
+			wantErr: ErrInsufficientSamples,
+		},
+		{
+			name:    "no-step",
+			method:  Tangent,
+			samples: constant,
+			wantErr: ErrNoStep,
+		},
+		{
+			name:    "no-response",
+			method:  Sundaresan,
+			samples: noResponse,
+			wantErr: ErrNoResponse,
+		},
+		{
+			name:    "irregular-sampling",
+			method:  LeastSquares,
+			samples: irregular,
+			wantErr: ErrIrregularSampling,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.method(tt.samples); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got %v, want: %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// withNoise adds Gaussian measurement noise with the given standard deviation
+// to the samples. The generator is seeded to keep the tests deterministic.
+func withNoise(samples []Sample, stddev float64) []Sample {
+	r := rand.New(rand.NewPCG(1, 2))
+	for i := range samples {
+		samples[i].Current += stddev * r.NormFloat64()
+	}
+	return samples
+}
+
+func checkModel(tb testing.TB, got, want pid.FOPDT, tolerance float64) {
+	tb.Helper()
+	if math.Abs(got.Gain-want.Gain) > tolerance*math.Abs(want.Gain) {
+		tb.Errorf("got gain %v, want: %v", got.Gain, want.Gain)
+	}
+	if math.Abs(got.TimeConstant-want.TimeConstant) > tolerance*want.TimeConstant {
+		tb.Errorf("got time constant %v, want: %v", got.TimeConstant, want.TimeConstant)
+	}
+	if math.Abs(got.DeadTime-want.DeadTime) > tolerance*want.TimeConstant {
+		tb.Errorf("got dead time %v, want: %v", got.DeadTime, want.DeadTime)
+	}
+}
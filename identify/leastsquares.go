@@ -0,0 +1,280 @@
+package identify
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/konradreiche/pid"
+)
+
+// ErrIrregularSampling is returned by least-squares methods when the samples
+// are not recorded at a regular interval.
+var ErrIrregularSampling = errors.New("identify: irregular sampling interval")
+
+// samplingJitter is the maximum deviation of a sampling interval relative to
+// the mean sampling interval.
+const samplingJitter = 0.1
+
+// maxDeadTime is the largest dead time searched by least-squares methods
+// relative to the duration of the record, such that enough samples remain to
+// fit the response after it.
+const maxDeadTime = 0.5
+
+// LeastSquares estimates a first-order-plus-dead-time model by fitting the
+// discrete-time model y[k+1] = a·y[k] + b·u[k-d] to the samples. The fit is
+// repeated for every dead time of d samples up to half the record and the one
+// with the smallest residual is chosen.
+//
+// Unlike the step response methods, it uses every sample and works with
+// arbitrary changes of the control signal, which makes it suitable for data
+// logged during regular operation. The process must be at steady state at the
+// first sample and the samples must be recorded at a regular interval.
+func LeastSquares(samples []Sample) (pid.FOPDT, error) {
+	fit, err := fitARX(samples, 1, 1)
+	if err != nil {
+		return pid.FOPDT{}, err
+	}
+	a, b := fit.coefficients[0], fit.coefficients[1]
+	if a <= 0.0 || a >= 1.0 {
+		return pid.FOPDT{}, fmt.Errorf("%w: fitted model is not stable", ErrNoResponse)
+	}
+	return pid.FOPDT{
+		Gain:         b / (1.0 - a),
+		TimeConstant: -fit.interval / math.Log(a),
+		DeadTime:     fit.deadTime,
+	}, nil
+}
+
+// LeastSquaresSecondOrder estimates a second-order-plus-dead-time model by
+// fitting the discrete-time model y[k+1] = a₁·y[k] + a₂·y[k-1] + b₁·u[k-d] +
+// b₂·u[k-d-1] to the samples, see [LeastSquares]. The process must be
+// overdamped, that is, it must not oscillate in response to a step. The fit is
+// more sensitive to measurement noise than [LeastSquares], noisy measurements
+// should be filtered first.
+func LeastSquaresSecondOrder(samples []Sample) (SecondOrder, error) {
+	fit, err := fitARX(samples, 2, 2)
+	if err != nil {
+		return SecondOrder{}, err
+	}
+	a1, a2 := fit.coefficients[0], fit.coefficients[1]
+	b1, b2 := fit.coefficients[2], fit.coefficients[3]
+
+	// The poles are the roots of z² - a₁·z - a₂, each real pole z in (0, 1)
+	// corresponds to a time constant of -Δt/ln(z).
+	discriminant := a1*a1 + 4.0*a2
+	if discriminant < 0.0 {
+		return SecondOrder{}, fmt.Errorf("%w: fitted model is not overdamped", ErrNoResponse)
+	}
+	z1 := (a1 + math.Sqrt(discriminant)) / 2.0
+	z2 := (a1 - math.Sqrt(discriminant)) / 2.0
+	if z1 <= 0.0 || z1 >= 1.0 || z2 <= 0.0 || z2 >= 1.0 {
+		return SecondOrder{}, fmt.Errorf("%w: fitted model is not stable", ErrNoResponse)
+	}
+	return SecondOrder{
+		Gain:          (b1 + b2) / (1.0 - a1 - a2),
+		TimeConstant1: -fit.interval / math.Log(z1),
+		TimeConstant2: -fit.interval / math.Log(z2),
+		DeadTime:      fit.deadTime,
+	}, nil
+}
+
+// arxFit is the result of fitting an autoregressive model with exogenous input
+// (ARX) to the samples.
+type arxFit struct {
+	// coefficients of the past outputs followed by the past inputs.
+	coefficients []float64
+	interval     float64
+	deadTime     float64
+}
+
+// fitARX fits y[k+1] = Σ aᵢ·y[k-i] + Σ bⱼ·u[k-d-j] in deviation from the first
+// sample, for every dead time d, and returns the fit with the smallest sum of
+// squared residuals.
+func fitARX(samples []Sample, outputs, inputs int) (arxFit, error) {
+	params := outputs + inputs
+	if len(samples) < 4*params {
+		return arxFit{}, fmt.Errorf("%w: got %d, want at least: %d", ErrInsufficientSamples, len(samples), 4*params)
+	}
+	interval := (samples[len(samples)-1].Time - samples[0].Time).Seconds() / float64(len(samples)-1)
+	for i := 1; i < len(samples); i++ {
+		dt := (samples[i].Time - samples[i-1].Time).Seconds()
+		if math.Abs(dt-interval) > samplingJitter*interval {
+			return arxFit{}, fmt.Errorf("%w: got %vs, want: %vs", ErrIrregularSampling, dt, interval)
+		}
+	}
+
+	n := len(samples)
+	y := make([]float64, n)
+	u := make([]float64, n)
+	for i, s := range samples {
+		y[i] = s.Current - samples[0].Current
+		u[i] = s.ControlSignal - samples[0].ControlSignal
+	}
+	// The process is at steady state at the first sample, so the deviations
+	// before it are zero. This lets every dead time be fitted over the same
+	// rows k = 0, …, n-2, otherwise a larger dead time would drop rows and win
+	// with a smaller sum of squared residuals.
+	at := func(values []float64, i int) float64 {
+		if i < 0 || i >= len(values) {
+			return 0.0
+		}
+		return values[i]
+	}
+
+	// The rows of different dead times only differ by the lag of the inputs,
+	// so the normal equations are assembled from sums computed once rather
+	// than from the rows of every dead time. The correlation holds
+	// Σ y[m]·u[m-l] over all samples for every lag l that occurs.
+	maxDelay := int(maxDeadTime * float64(n))
+	minLag, maxLag := -(outputs - 1), maxDelay+inputs
+	correlation := make([]float64, maxLag-minLag+1)
+	for l := minLag; l <= maxLag; l++ {
+		var sum float64
+		for m := max(l, 0); m < min(n, n+l); m++ {
+			sum += y[m] * u[m-l]
+		}
+		correlation[l-minLag] = sum
+	}
+	// crossSum returns Σ y[m]·u[m-lag] for m = 0, …, end.
+	crossSum := func(lag, end int) float64 {
+		sum := correlation[lag-minLag]
+		for m := end + 1; m < n; m++ {
+			sum -= y[m] * at(u, m-lag)
+		}
+		return sum
+	}
+	// inputSums[δ][e] holds Σ u[m]·u[m+δ] for m = 0, …, e-1.
+	inputSums := make(map[int][]float64, 2*inputs-1)
+	for delta := -(inputs - 1); delta < inputs; delta++ {
+		sums := make([]float64, n)
+		for m := range n - 1 {
+			sums[m+1] = sums[m] + u[m]*at(u, m+delta)
+		}
+		inputSums[delta] = sums
+	}
+	inputSum := func(delta, end int) float64 {
+		if end < 0 {
+			return 0.0
+		}
+		return inputSums[delta][end+1]
+	}
+
+	// The sums over the past outputs do not depend on the dead time.
+	outputSums := make([][]float64, outputs)
+	outputTargets := make([]float64, outputs)
+	var targets float64
+	for k := range n - 1 {
+		for i := range outputs {
+			if outputSums[i] == nil {
+				outputSums[i] = make([]float64, outputs)
+			}
+			for j := range outputs {
+				outputSums[i][j] += at(y, k-i) * at(y, k-j)
+			}
+			outputTargets[i] += at(y, k-i) * y[k+1]
+		}
+		targets += y[k+1] * y[k+1]
+	}
+
+	best := arxFit{interval: interval}
+	bestResidual := math.Inf(1)
+	a := make([][]float64, params)
+	for i := range a {
+		a[i] = make([]float64, params)
+	}
+	b := make([]float64, params)
+	for d := 0; d <= maxDelay; d++ {
+		for i := range outputs {
+			copy(a[i], outputSums[i])
+			b[i] = outputTargets[i]
+			for j := range inputs {
+				// Σ y[k-i]·u[k-d-j] for k = 0, …, n-2.
+				a[i][outputs+j] = crossSum(d+j-i, n-2-i)
+				a[outputs+j][i] = a[i][outputs+j]
+			}
+		}
+		for i := range inputs {
+			for j := range inputs {
+				// Σ u[k-d-i]·u[k-d-j] for k = 0, …, n-2.
+				a[outputs+i][outputs+j] = inputSum(i-j, n-2-d-i)
+			}
+			// Σ u[k-d-i]·y[k+1] for k = 0, …, n-2.
+			b[outputs+i] = crossSum(d+i+1, n-1)
+		}
+		coefficients, residual, err := leastSquares(a, b, targets)
+		if err != nil {
+			continue
+		}
+		if residual < bestResidual {
+			bestResidual = residual
+			best.coefficients = coefficients
+			best.deadTime = float64(d) * interval
+		}
+	}
+	if best.coefficients == nil {
+		return arxFit{}, ErrNoResponse
+	}
+	return best, nil
+}
+
+// leastSquares solves the normal equations a·x = b of the linear
+// least-squares problem and returns the coefficients and the sum of squared
+// residuals, given the sum of the squared targets. The inputs are not
+// modified.
+func leastSquares(a [][]float64, b []float64, targets float64) ([]float64, float64, error) {
+	n := len(b)
+	aa := make([][]float64, n)
+	for i := range n {
+		aa[i] = slices.Clone(a[i])
+	}
+	x, err := solve(aa, slices.Clone(b))
+	if err != nil {
+		return nil, 0.0, err
+	}
+	// The sum of squared residuals is Σt² - 2·xᵀb + xᵀ·a·x.
+	residual := targets
+	for i := range n {
+		residual -= 2.0 * x[i] * b[i]
+		for j := range n {
+			residual += x[i] * a[i][j] * x[j]
+		}
+	}
+	return x, residual, nil
+}
+
+// solve solves the linear system a·x = b using Gaussian elimination with
+// partial pivoting. The inputs are modified in place.
+func solve(a [][]float64, b []float64) ([]float64, error) {
+	n := len(b)
+	for col := range n {
+		pivot := col
+		for row := col + 1; row < n; row++ {
+			if math.Abs(a[row][col]) > math.Abs(a[pivot][col]) {
+				pivot = row
+			}
+		}
+		if math.Abs(a[pivot][col]) < 1e-12 {
+			return nil, errors.New("identify: singular system")
+		}
+		a[col], a[pivot] = a[pivot], a[col]
+		b[col], b[pivot] = b[pivot], b[col]
+		for row := col + 1; row < n; row++ {
+			factor := a[row][col] / a[col][col]
+			for j := col; j < n; j++ {
+				a[row][j] -= factor * a[col][j]
+			}
+			b[row] -= factor * b[col]
+		}
+	}
+	x := make([]float64, n)
+	for row := n - 1; row >= 0; row-- {
+		sum := b[row]
+		for j := row + 1; j < n; j++ {
+			sum -= a[row][j] * x[j]
+		}
+		x[row] = sum / a[row][row]
+	}
+	return x, nil
+}
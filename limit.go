@@ -19,3 +19,10 @@ func newLimit(lower, upper float64) limit {
 func (l limit) apply(value float64) float64 {
 	return min(max(l.lower, value), l.upper)
 }
+
+// applyRate ensures that the given value changes from the previous value by no
+// more than the limits per second over the given step, where `lower` is the
+// maximum fall as a negative rate and `upper` is the maximum rise.
+func (l limit) applyRate(value, prev, step float64) float64 {
+	return min(max(prev+l.lower*step, value), prev+l.upper*step)
+}
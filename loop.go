@@ -0,0 +1,278 @@
+package pid
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// Sensor returns the current measurement of the process.
+type Sensor func() (float64, error)
+
+// Actuator applies the control signal to the process.
+type Actuator func(controlSignal float64)
+
+// TargetSource returns the current target, which may change over time.
+type TargetSource func() float64
+
+// LoopController is the controller run by a [*Loop]. It is implemented by
+// [*Controller] and by [*SafeController], which allows using the controller
+// from other goroutines while the loop is running, for example to retune it.
+type LoopController interface {
+	UpdateAt(target, current float64, now time.Time) float64
+	SetManual(output float64)
+	SetAuto()
+	Manual() bool
+}
+
+// SensorErrorPolicy selects how a [*Loop] responds to a [Sensor] error.
+type SensorErrorPolicy int
+
+const (
+	// SensorErrorHold applies the last control signal again and resumes
+	// control once the sensor recovers. The time without measurements is not
+	// integrated, the first update after the recovery is measured from the
+	// last iteration with a sensor error.
+	SensorErrorHold SensorErrorPolicy = iota
+	// SensorErrorFailSafe applies the fail-safe output configured with
+	// [WithFailSafeOutput]. A controller in automatic mode is switched to
+	// manual mode in the meantime, such that control resumes bumpless from the
+	// fail-safe output once the sensor recovers. A controller that an operator
+	// switched to manual mode is left untouched and keeps its manual output
+	// after the recovery.
+	SensorErrorFailSafe
+	// SensorErrorStop stops the loop and returns the error from [Loop.Run].
+	SensorErrorStop
+)
+
+// LoopStats reports the timing of a [*Loop]. Jitter is the deviation of the
+// time between two iterations from the interval. An overrun is an iteration
+// that took longer than the interval, causing the next tick to be dropped.
+type LoopStats struct {
+	Iterations   uint64
+	SensorErrors uint64
+	Overruns     uint64
+	LastJitter   time.Duration
+	MaxJitter    time.Duration
+	LastDuration time.Duration
+	MaxDuration  time.Duration
+}
+
+// Loop runs a [LoopController] in the background: at every interval it reads
+// the measurement from the sensor, updates the controller with the target,
+// and applies the control signal to the actuator. The time step of each update
+// is the time elapsed since the previous one, see [Controller.UpdateAt],
+// measured with the clock configured by [WithClock]. For other implementations
+// of [LoopController], the system clock is used.
+//
+// Pass a [*SafeController] to use the controller concurrently while the loop
+// is running.
+type Loop struct {
+	controller LoopController
+	target     TargetSource
+	sensor     Sensor
+	actuator   Actuator
+	interval   time.Duration
+
+	// Clock and output limit of the controller, if known.
+	clock       Clock
+	outputLimit limit
+
+	sensorErrorPolicy SensorErrorPolicy
+	failSafeOutput    float64
+
+	// Last control signal applied to the actuator, and whether the controller
+	// was switched to manual mode due to a sensor error.
+	output   float64
+	applied  bool
+	failSafe bool
+
+	// Time of the last update and of the last sensor error since then. The
+	// time passed to the controller lags behind by the accumulated outages,
+	// such that they are not integrated.
+	lastUpdate      time.Time
+	lastSensorError time.Time
+	outage          time.Duration
+
+	mu    sync.Mutex
+	stats LoopStats
+}
+
+// NewLoop constructs a [*Loop] that updates the controller at the given
+// interval, configured by the provided options.
+func NewLoop(
+	controller LoopController,
+	target TargetSource,
+	sensor Sensor,
+	actuator Actuator,
+	interval time.Duration,
+	opts ...LoopOption,
+) (*Loop, error) {
+	if controller == nil || target == nil || sensor == nil || actuator == nil {
+		return nil, errors.New("loop: controller, target, sensor, and actuator must not be nil")
+	}
+	if interval <= 0 {
+		return nil, fmt.Errorf("loop: interval must be positive, got: %v", interval)
+	}
+	l := &Loop{
+		controller:  controller,
+		target:      target,
+		sensor:      sensor,
+		actuator:    actuator,
+		interval:    interval,
+		clock:       systemClock{},
+		outputLimit: newLimit(math.Inf(-1), math.Inf(1)),
+	}
+	switch c := controller.(type) {
+	case *Controller:
+		l.clock, l.outputLimit = c.clock, c.outputLimit
+	case *SafeController:
+		l.clock, l.outputLimit = c.controller.clock, c.controller.outputLimit
+	}
+	for _, opt := range opts {
+		if err := opt(l); err != nil {
+			return nil, err
+		}
+	}
+	return l, nil
+}
+
+// Run runs the loop until the context is canceled, starting with an immediate
+// iteration. It returns the error of the context, or the sensor error if the
+// loop was stopped by [SensorErrorStop].
+//
+// The first update only establishes the time reference of a fresh controller,
+// see [Controller.UpdateAt], the actuator is not called until the second
+// update.
+func (l *Loop) Run(ctx context.Context) error {
+	ticker := time.NewTicker(l.interval)
+	defer ticker.Stop()
+
+	var prev time.Time
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		now := l.clock.Now()
+		if err := l.iterate(now, prev); err != nil {
+			return err
+		}
+		prev = now
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// iterate runs a single iteration of the loop at the given tick.
+func (l *Loop) iterate(now, prev time.Time) error {
+	measurement, err := l.sensor()
+	defer l.record(now, prev, err != nil)
+	if err != nil {
+		l.lastSensorError = now
+		switch l.sensorErrorPolicy {
+		case SensorErrorStop:
+			return fmt.Errorf("loop: sensor: %w", err)
+		case SensorErrorFailSafe:
+			if !l.failSafe && !l.controller.Manual() {
+				l.failSafe = true
+				l.controller.SetManual(l.failSafeOutput)
+			}
+			l.apply(l.failSafeOutput)
+		default:
+			if l.applied {
+				l.apply(l.output)
+			}
+		}
+		return nil
+	}
+
+	if l.failSafe {
+		l.failSafe = false
+		l.controller.SetAuto()
+	}
+	first := l.lastUpdate.IsZero()
+	if !first && !l.lastSensorError.IsZero() {
+		l.outage += l.lastSensorError.Sub(l.lastUpdate)
+	}
+	l.lastUpdate = now
+	l.lastSensorError = time.Time{}
+	controlSignal := l.controller.UpdateAt(l.target(), measurement, now.Add(-l.outage))
+	if !first {
+		l.apply(controlSignal)
+	}
+	return nil
+}
+
+// apply passes the control signal to the actuator.
+func (l *Loop) apply(controlSignal float64) {
+	l.actuator(controlSignal)
+	l.output = controlSignal
+	l.applied = true
+}
+
+// record updates the statistics of the iteration started at the given tick.
+func (l *Loop) record(now, prev time.Time, sensorError bool) {
+	duration := l.clock.Now().Sub(now)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.stats.Iterations++
+	if sensorError {
+		l.stats.SensorErrors++
+	}
+	if !prev.IsZero() {
+		jitter := now.Sub(prev) - l.interval
+		if jitter < 0 {
+			jitter = -jitter
+		}
+		l.stats.LastJitter = jitter
+		l.stats.MaxJitter = max(l.stats.MaxJitter, jitter)
+	}
+	l.stats.LastDuration = duration
+	l.stats.MaxDuration = max(l.stats.MaxDuration, duration)
+	if duration > l.interval {
+		l.stats.Overruns++
+	}
+}
+
+// Stats returns the current [LoopStats]. It is safe to call while the loop is
+// running.
+func (l *Loop) Stats() LoopStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.stats
+}
+
+// LoopOption is a functional option for the configuration of [*Loop].
+type LoopOption func(*Loop) error
+
+// WithSensorErrorPolicy selects how the loop responds to sensor errors. By
+// default, the last control signal is held.
+func WithSensorErrorPolicy(policy SensorErrorPolicy) LoopOption {
+	return func(l *Loop) error {
+		switch policy {
+		case SensorErrorHold, SensorErrorFailSafe, SensorErrorStop:
+		default:
+			return fmt.Errorf("loop: unknown sensor error policy: %d", policy)
+		}
+		l.sensorErrorPolicy = policy
+		return nil
+	}
+}
+
+// WithFailSafeOutput sets the control signal applied by [SensorErrorFailSafe],
+// zero by default. It is clamped to the output limit of a [*Controller] or
+// [*SafeController], other implementations of [LoopController] must ensure
+// that it is within their output limit.
+func WithFailSafeOutput(output float64) LoopOption {
+	return func(l *Loop) error {
+		l.failSafeOutput = l.outputLimit.apply(output)
+		return nil
+	}
+}
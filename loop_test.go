@@ -0,0 +1,341 @@
+package pid
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// errSensor is returned by the sensor in tests.
+var errSensor = errors.New("sensor unavailable")
+
+func TestLoop_SensorErrorPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    []LoopOption
+		want    []float64
+		wantErr error
+	}{
+		{
+			name: "hold",
+			want: []float64{8, 8, 6},
+		},
+		{
+			name: "fail-safe",
+			opts: []LoopOption{
+				WithSensorErrorPolicy(SensorErrorFailSafe),
+				WithFailSafeOutput(-1),
+			},
+			want: []float64{8, -1, 6},
+		},
+		{
+			name:    "stop",
+			opts:    []LoopOption{WithSensorErrorPolicy(SensorErrorStop)},
+			want:    []float64{8},
+			wantErr: errSensor,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pid, err := New()
+			if err != nil {
+				t.Fatal(err)
+			}
+			ctx, cancel := context.WithCancel(t.Context())
+			defer cancel()
+
+			// The first measurement only establishes the time reference.
+			measurements := []float64{0, 2, -1, 4}
+			var i int
+			sensor := func() (float64, error) {
+				if i == len(measurements) {
+					cancel()
+					return 0, errSensor
+				}
+				measurement := measurements[i]
+				i++
+				if measurement < 0 {
+					return 0, errSensor
+				}
+				return measurement, nil
+			}
+			var got []float64
+			actuator := func(controlSignal float64) {
+				got = append(got, controlSignal)
+			}
+			target := func() float64 { return 10 }
+
+			loop, err := NewLoop(pid, target, sensor, actuator, time.Millisecond, tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = loop.Run(ctx)
+			wantErr := tt.wantErr
+			if wantErr == nil {
+				wantErr = context.Canceled
+			}
+			if !errors.Is(err, wantErr) {
+				t.Fatalf("got %v, want: %v", err, wantErr)
+			}
+			// The sensor error after cancellation may add a final control signal.
+			if diff := cmp.Diff(got[:len(tt.want)], tt.want); diff != "" {
+				t.Errorf("diff: %s", diff)
+			}
+		})
+	}
+}
+
+func TestLoop_FailSafeIsBumpless(t *testing.T) {
+	pid, err := New(WithProportionalGain(0.0), WithIntegralGain(1.0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+
+	readings := []error{nil, errSensor, nil}
+	var i int
+	sensor := func() (float64, error) {
+		if i == len(readings) {
+			cancel()
+			return 10, nil
+		}
+		err := readings[i]
+		i++
+		return 10, err
+	}
+	var got []float64
+	actuator := func(controlSignal float64) {
+		got = append(got, controlSignal)
+	}
+	target := func() float64 { return 10 }
+
+	loop, err := NewLoop(pid, target, sensor, actuator, time.Millisecond,
+		WithSensorErrorPolicy(SensorErrorFailSafe),
+		WithFailSafeOutput(5),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := loop.Run(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("got %v, want: %v", err, context.Canceled)
+	}
+	// Without error the integral controller resumes from the fail-safe output.
+	if diff := cmp.Diff(got[:2], []float64{5, 5}); diff != "" {
+		t.Errorf("diff: %s", diff)
+	}
+	if pid.manual {
+		t.Error("controller must be switched back to automatic mode")
+	}
+}
+
+func TestLoop_FailSafeKeepsManualMode(t *testing.T) {
+	pid, err := NewSafe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pid.SetManual(3)
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+
+	readings := []error{nil, errSensor, nil}
+	var i int
+	sensor := func() (float64, error) {
+		if i == len(readings) {
+			cancel()
+			return 10, nil
+		}
+		err := readings[i]
+		i++
+		return 10, err
+	}
+	var got []float64
+	actuator := func(controlSignal float64) {
+		got = append(got, controlSignal)
+	}
+	target := func() float64 { return 10 }
+
+	loop, err := NewLoop(pid, target, sensor, actuator, time.Millisecond,
+		WithSensorErrorPolicy(SensorErrorFailSafe),
+		WithFailSafeOutput(5),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := loop.Run(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("got %v, want: %v", err, context.Canceled)
+	}
+	// The operator's manual output is restored once the sensor recovers.
+	if diff := cmp.Diff(got[:2], []float64{5, 3}); diff != "" {
+		t.Errorf("diff: %s", diff)
+	}
+	if !pid.Manual() {
+		t.Error("controller must remain in manual mode")
+	}
+}
+
+func TestLoop_SensorErrorHoldSkipsOutage(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	pid, err := New(
+		WithProportionalGain(0.0),
+		WithIntegralGain(1.0),
+		WithClock(clock),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The sensor fails for ten iterations after the first two.
+	var i int
+	sensor := func() (float64, error) {
+		i++
+		if i > 2 && i <= 12 {
+			return 0, errSensor
+		}
+		return 9, nil
+	}
+	var got []float64
+	actuator := func(controlSignal float64) {
+		got = append(got, controlSignal)
+	}
+	target := func() float64 { return 10 }
+
+	loop, err := NewLoop(pid, target, sensor, actuator, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var prev time.Time
+	for range 13 {
+		if err := loop.iterate(clock.now, prev); err != nil {
+			t.Fatal(err)
+		}
+		prev = clock.now
+		clock.advance(time.Second)
+	}
+	// The update after the outage integrates a single interval.
+	if got, want := got[len(got)-1], 2.0; got != want {
+		t.Errorf("got %v, want: %v", got, want)
+	}
+}
+
+func TestLoop_SafeController(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	pid, err := NewSafe(WithClock(clock))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+
+	var i int
+	sensor := func() (float64, error) {
+		i++
+		if i == 2 {
+			cancel()
+		}
+		clock.advance(time.Millisecond)
+		return 4, nil
+	}
+	var got []float64
+	actuator := func(controlSignal float64) {
+		got = append(got, controlSignal)
+	}
+	target := func() float64 { return 10 }
+
+	loop, err := NewLoop(pid, target, sensor, actuator, time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := loop.Run(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("got %v, want: %v", err, context.Canceled)
+	}
+	if diff := cmp.Diff(got, []float64{6}); diff != "" {
+		t.Errorf("diff: %s", diff)
+	}
+}
+
+func TestLoop_Stats(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	pid, err := New(WithClock(clock))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+
+	var i int
+	sensor := func() (float64, error) {
+		i++
+		if i == 3 {
+			cancel()
+		}
+		return 0, nil
+	}
+	// The actuator takes longer than the interval.
+	actuator := func(float64) {
+		clock.advance(5 * time.Millisecond)
+	}
+	target := func() float64 { return 1 }
+
+	loop, err := NewLoop(pid, target, sensor, actuator, time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := loop.Run(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("got %v, want: %v", err, context.Canceled)
+	}
+
+	// The first iteration does not call the actuator, the following ones
+	// overrun and start late.
+	want := LoopStats{
+		Iterations:   3,
+		Overruns:     2,
+		LastJitter:   4 * time.Millisecond,
+		MaxJitter:    4 * time.Millisecond,
+		LastDuration: 5 * time.Millisecond,
+		MaxDuration:  5 * time.Millisecond,
+	}
+	if diff := cmp.Diff(loop.Stats(), want); diff != "" {
+		t.Errorf("diff: %s", diff)
+	}
+}
+
+func TestNewLoop_InvalidOptions(t *testing.T) {
+	pid, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sensor := func() (float64, error) { return 0, nil }
+	actuator := func(float64) {}
+	target := func() float64 { return 0 }
+
+	tests := []struct {
+		name     string
+		interval time.Duration
+		opts     []LoopOption
+	}{
+		{
+			name:     "non-positive-interval",
+			interval: 0,
+		},
+		{
+			name:     "unknown-sensor-error-policy",
+			interval: time.Second,
+			opts:     []LoopOption{WithSensorErrorPolicy(SensorErrorPolicy(-1))},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewLoop(pid, target, sensor, actuator, tt.interval, tt.opts...); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+	if _, err := NewLoop(pid, target, nil, actuator, time.Second); err == nil {
+		t.Fatal("expected error")
+	}
+}
@@ -7,48 +7,198 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 )
 
-const nameLabel = "name"
+const (
+	nameLabel   = "name"
+	reasonLabel = "reason"
+	boundLabel  = "bound"
+)
 
 var labels = []string{
 	nameLabel,
 }
 
+// timeStepBuckets are the histogram buckets of the time step, from 1ms to
+// about 30s.
+var timeStepBuckets = prometheus.ExponentialBuckets(0.001, 2, 16)
+
+// recorder records the signals of a controller to a metrics backend.
+type recorder interface {
+	// update records the inputs of an accepted update.
+	update(target, current, feedforward, step float64)
+	// reject records an update rejected for the reason of the given error.
+	reject(err error)
+	// output records the control signal of an update.
+	output(controlSignal float64)
+	// increment records the change of the control signal of an update of a
+	// [VelocityController].
+	increment(increment float64)
+	// terms records the internals of the controller after an update.
+	terms(t terms)
+	// saturate records the time spent saturated at the given bound.
+	saturate(bound string, seconds float64)
+	// close stops recording and removes the recorded series, if the backend
+	// supports it.
+	close()
+}
+
+// terms are the internals of the controller after an update.
+type terms struct {
+	controlError      float64
+	proportional      float64
+	integral          float64
+	derivative        float64
+	integralState     float64
+	unsaturatedOutput float64
+}
+
+// rejectReason returns the reason recorded for a rejected update.
+func rejectReason(err error) string {
+	if errors.Is(err, ErrInvalidTimeStep) {
+		return "invalid_time_step"
+	}
+	return "invalid_input"
+}
+
+// metrics is the Prometheus [recorder].
 type metrics struct {
 	updatesTotal  *prometheus.CounterVec
+	rejectedTotal *prometheus.CounterVec
 	target        *prometheus.GaugeVec
 	current       *prometheus.GaugeVec
 	controlSignal *prometheus.GaugeVec
+	feedforward   *prometheus.GaugeVec
+
+	// Change of the control signal, only recorded by [VelocityController].
+	controlSignalIncrement *prometheus.GaugeVec
+
+	// Internals of the controller for debugging the tuning.
+	controlError      *prometheus.GaugeVec
+	proportionalTerm  *prometheus.GaugeVec
+	integralTerm      *prometheus.GaugeVec
+	derivativeTerm    *prometheus.GaugeVec
+	integral          *prometheus.GaugeVec
+	unsaturatedOutput *prometheus.GaugeVec
+	saturatedSeconds  *prometheus.CounterVec
+	timeStepSeconds   *prometheus.HistogramVec
 
 	labels prometheus.Labels
 }
 
-func newMetrics(name string, reg prometheus.Registerer) (*metrics, error) {
+// metricsOptions configures the names, help strings, and constant labels of
+// the metrics.
+type metricsOptions struct {
+	namespace   string
+	subsystem   string
+	constLabels prometheus.Labels
+	help        map[string]string
+}
+
+// defaultHelp is the help string of each metric by name, without namespace and
+// subsystem.
+var defaultHelp = map[string]string{
+	"updates_total":            "Number of updates.",
+	"rejected_updates_total":   "Number of updates rejected due to an invalid time step or input.",
+	"target":                   "Target of the last update.",
+	"current":                  "Measurement of the last update.",
+	"control_signal":           "Control signal of the last update.",
+	"control_signal_increment": "Change of the control signal of the last update of a velocity controller.",
+	"feedforward":              "Feedforward term of the last update.",
+	"error":                    "Error driving the proportional, integral, and derivative terms.",
+	"proportional_term":        "Contribution of the proportional term to the output.",
+	"integral_term":            "Contribution of the integral term to the output.",
+	"derivative_term":          "Contribution of the derivative term to the output.",
+	"integral":                 "Integral of the error.",
+	"unsaturated_output":       "Output before the output limits are applied.",
+	"saturated_seconds_total":  "Time spent saturated at the lower or upper output limit.",
+	"time_step_seconds":        "Time step of the updates.",
+}
+
+// opts returns the options of the metric with the given name.
+func (o metricsOptions) opts(name string) prometheus.Opts {
+	help, ok := o.help[name]
+	if !ok {
+		help = defaultHelp[name]
+	}
+	return prometheus.Opts{
+		Namespace:   o.namespace,
+		Subsystem:   o.subsystem,
+		Name:        name,
+		Help:        help,
+		ConstLabels: o.constLabels,
+	}
+}
+
+func newMetrics(name string, reg prometheus.Registerer, o metricsOptions) (*metrics, error) {
 	m := &metrics{
 		labels: prometheus.Labels{
 			nameLabel: name,
 		},
 	}
 	var err error
-	m.updatesTotal, err = register(reg, prometheus.NewCounterVec(prometheus.CounterOpts{
-		Name: "pid_updates_total",
-	}, labels))
+	m.updatesTotal, err = register(reg, prometheus.NewCounterVec(prometheus.CounterOpts(o.opts("updates_total")), labels))
 	if err != nil {
 		return nil, err
 	}
-	m.target, err = register(reg, prometheus.NewGaugeVec(prometheus.GaugeOpts{
-		Name: "pid_target",
-	}, labels))
+	m.rejectedTotal, err = register(reg, prometheus.NewCounterVec(prometheus.CounterOpts(o.opts("rejected_updates_total")), []string{nameLabel, reasonLabel}))
 	if err != nil {
 		return nil, err
 	}
-	m.current, err = register(reg, prometheus.NewGaugeVec(prometheus.GaugeOpts{
-		Name: "pid_current",
-	}, labels))
+	m.target, err = register(reg, prometheus.NewGaugeVec(prometheus.GaugeOpts(o.opts("target")), labels))
+	if err != nil {
+		return nil, err
+	}
+	m.current, err = register(reg, prometheus.NewGaugeVec(prometheus.GaugeOpts(o.opts("current")), labels))
+	if err != nil {
+		return nil, err
+	}
+	m.controlSignal, err = register(reg, prometheus.NewGaugeVec(prometheus.GaugeOpts(o.opts("control_signal")), labels))
+	if err != nil {
+		return nil, err
+	}
+	m.controlSignalIncrement, err = register(reg, prometheus.NewGaugeVec(prometheus.GaugeOpts(o.opts("control_signal_increment")), labels))
+	if err != nil {
+		return nil, err
+	}
+	m.feedforward, err = register(reg, prometheus.NewGaugeVec(prometheus.GaugeOpts(o.opts("feedforward")), labels))
+	if err != nil {
+		return nil, err
+	}
+	m.controlError, err = register(reg, prometheus.NewGaugeVec(prometheus.GaugeOpts(o.opts("error")), labels))
+	if err != nil {
+		return nil, err
+	}
+	m.proportionalTerm, err = register(reg, prometheus.NewGaugeVec(prometheus.GaugeOpts(o.opts("proportional_term")), labels))
+	if err != nil {
+		return nil, err
+	}
+	m.integralTerm, err = register(reg, prometheus.NewGaugeVec(prometheus.GaugeOpts(o.opts("integral_term")), labels))
+	if err != nil {
+		return nil, err
+	}
+	m.derivativeTerm, err = register(reg, prometheus.NewGaugeVec(prometheus.GaugeOpts(o.opts("derivative_term")), labels))
+	if err != nil {
+		return nil, err
+	}
+	m.integral, err = register(reg, prometheus.NewGaugeVec(prometheus.GaugeOpts(o.opts("integral")), labels))
+	if err != nil {
+		return nil, err
+	}
+	m.unsaturatedOutput, err = register(reg, prometheus.NewGaugeVec(prometheus.GaugeOpts(o.opts("unsaturated_output")), labels))
+	if err != nil {
+		return nil, err
+	}
+	m.saturatedSeconds, err = register(reg, prometheus.NewCounterVec(prometheus.CounterOpts(o.opts("saturated_seconds_total")), []string{nameLabel, boundLabel}))
 	if err != nil {
 		return nil, err
 	}
-	m.controlSignal, err = register(reg, prometheus.NewGaugeVec(prometheus.GaugeOpts{
-		Name: "pid_control_signal",
+	timeStepOpts := o.opts("time_step_seconds")
+	m.timeStepSeconds, err = register(reg, prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace:   timeStepOpts.Namespace,
+		Subsystem:   timeStepOpts.Subsystem,
+		Name:        timeStepOpts.Name,
+		Help:        timeStepOpts.Help,
+		ConstLabels: timeStepOpts.ConstLabels,
+		Buckets:     timeStepBuckets,
 	}, labels))
 	if err != nil {
 		return nil, err
@@ -56,6 +206,62 @@ func newMetrics(name string, reg prometheus.Registerer) (*metrics, error) {
 	return m, nil
 }
 
+func (m *metrics) update(target, current, feedforward, step float64) {
+	m.updatesTotal.With(m.labels).Inc()
+	m.target.With(m.labels).Set(target)
+	m.current.With(m.labels).Set(current)
+	m.feedforward.With(m.labels).Set(feedforward)
+	m.timeStepSeconds.With(m.labels).Observe(step)
+}
+
+func (m *metrics) reject(err error) {
+	m.rejectedTotal.MustCurryWith(m.labels).WithLabelValues(rejectReason(err)).Inc()
+}
+
+func (m *metrics) output(controlSignal float64) {
+	m.controlSignal.With(m.labels).Set(controlSignal)
+}
+
+func (m *metrics) increment(increment float64) {
+	m.controlSignalIncrement.With(m.labels).Set(increment)
+}
+
+func (m *metrics) terms(t terms) {
+	m.controlError.With(m.labels).Set(t.controlError)
+	m.proportionalTerm.With(m.labels).Set(t.proportional)
+	m.integralTerm.With(m.labels).Set(t.integral)
+	m.derivativeTerm.With(m.labels).Set(t.derivative)
+	m.integral.With(m.labels).Set(t.integralState)
+	m.unsaturatedOutput.With(m.labels).Set(t.unsaturatedOutput)
+}
+
+func (m *metrics) saturate(bound string, seconds float64) {
+	m.saturatedSeconds.MustCurryWith(m.labels).WithLabelValues(bound).Add(seconds)
+}
+
+// close removes the series of the controller from all vectors.
+func (m *metrics) close() {
+	for _, vec := range []interface{ DeletePartialMatch(prometheus.Labels) int }{
+		m.updatesTotal,
+		m.rejectedTotal,
+		m.target,
+		m.current,
+		m.controlSignal,
+		m.controlSignalIncrement,
+		m.feedforward,
+		m.controlError,
+		m.proportionalTerm,
+		m.integralTerm,
+		m.derivativeTerm,
+		m.integral,
+		m.unsaturatedOutput,
+		m.saturatedSeconds,
+		m.timeStepSeconds,
+	} {
+		vec.DeletePartialMatch(m.labels)
+	}
+}
+
 func register[C prometheus.Collector](reg prometheus.Registerer, collector C) (C, error) {
 	var c C
 	if err := reg.Register(collector); err != nil {
@@ -71,3 +277,59 @@ func register[C prometheus.Collector](reg prometheus.Registerer, collector C) (C
 	}
 	return collector, nil
 }
+
+// MetricsOption is a functional option for the configuration of the metrics
+// enabled by [WithPrometheusMetrics].
+type MetricsOption func(*metricsOptions) error
+
+// WithMetricsNamespace sets the namespace prefixed to the metric names, "pid"
+// by default.
+func WithMetricsNamespace(namespace string) MetricsOption {
+	return func(o *metricsOptions) error {
+		o.namespace = namespace
+		return nil
+	}
+}
+
+// WithMetricsSubsystem sets the subsystem inserted between the namespace and
+// the metric names, empty by default.
+func WithMetricsSubsystem(subsystem string) MetricsOption {
+	return func(o *metricsOptions) error {
+		o.subsystem = subsystem
+		return nil
+	}
+}
+
+// WithMetricsConstLabels adds constant labels, such as the service or region,
+// to all metrics. Controllers registered with the same registerer must use the
+// same label names, but may use different values.
+func WithMetricsConstLabels(constLabels prometheus.Labels) MetricsOption {
+	return func(o *metricsOptions) error {
+		if o.constLabels == nil {
+			o.constLabels = make(prometheus.Labels, len(constLabels))
+		}
+		for label, value := range constLabels {
+			switch label {
+			case nameLabel, reasonLabel, boundLabel:
+				return fmt.Errorf("metrics: constant label %q is reserved", label)
+			}
+			o.constLabels[label] = value
+		}
+		return nil
+	}
+}
+
+// WithMetricsHelp overrides the help string of the metric with the given name,
+// without namespace and subsystem, such as "control_signal".
+func WithMetricsHelp(metric, help string) MetricsOption {
+	return func(o *metricsOptions) error {
+		if _, ok := defaultHelp[metric]; !ok {
+			return fmt.Errorf("metrics: unknown metric: %q", metric)
+		}
+		if o.help == nil {
+			o.help = make(map[string]string)
+		}
+		o.help[metric] = help
+		return nil
+	}
+}
@@ -1,6 +1,8 @@
 package pid
 
 import (
+	"fmt"
+	"math"
 	"testing"
 	"time"
 
@@ -10,11 +12,13 @@ import (
 
 func TestMetrics_Update(t *testing.T) {
 	tests := []struct {
-		name    string
-		value   func(m *metrics) float64
-		target  float64
-		current float64
-		want    float64
+		name        string
+		opts        []Option
+		value       func(m *metrics) float64
+		target      float64
+		current     float64
+		feedforward float64
+		want        float64
 	}{
 		{
 			name:    "pid_updates_total",
@@ -44,16 +48,71 @@ func TestMetrics_Update(t *testing.T) {
 			current: 1.0,
 			want:    4.0,
 		},
+		{
+			name:        "pid_feedforward",
+			value:       func(m *metrics) float64 { return testutil.ToFloat64(m.feedforward) },
+			target:      5.0,
+			current:     1.0,
+			feedforward: 3.0,
+			want:        3.0,
+		},
+		{
+			name:    "pid_error",
+			value:   func(m *metrics) float64 { return testutil.ToFloat64(m.controlError) },
+			target:  5.0,
+			current: 1.0,
+			want:    4.0,
+		},
+		{
+			name:    "pid_proportional_term",
+			opts:    []Option{WithProportionalGain(2.0)},
+			value:   func(m *metrics) float64 { return testutil.ToFloat64(m.proportionalTerm) },
+			target:  5.0,
+			current: 1.0,
+			want:    8.0,
+		},
+		{
+			name:    "pid_integral_term",
+			opts:    []Option{WithIntegralGain(0.5)},
+			value:   func(m *metrics) float64 { return testutil.ToFloat64(m.integralTerm) },
+			target:  5.0,
+			current: 1.0,
+			want:    2.0,
+		},
+		{
+			name:    "pid_derivative_term",
+			opts:    []Option{WithDerivativeGain(0.25)},
+			value:   func(m *metrics) float64 { return testutil.ToFloat64(m.derivativeTerm) },
+			target:  5.0,
+			current: 1.0,
+			want:    1.0,
+		},
+		{
+			name:    "pid_integral",
+			opts:    []Option{WithIntegralGain(0.5)},
+			value:   func(m *metrics) float64 { return testutil.ToFloat64(m.integral) },
+			target:  5.0,
+			current: 1.0,
+			want:    4.0,
+		},
+		{
+			name:    "pid_unsaturated_output",
+			opts:    []Option{WithOutputLimit(-2.0, 2.0)},
+			value:   func(m *metrics) float64 { return testutil.ToFloat64(m.unsaturatedOutput) },
+			target:  5.0,
+			current: 1.0,
+			want:    4.0,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			registry := prometheus.NewRegistry()
-			controller, err := New(WithPrometheusMetrics(t.Name(), registry))
+			controller, err := New(append(tt.opts, WithPrometheusMetrics(t.Name(), registry))...)
 			if err != nil {
 				t.Fatal(err)
 			}
-			controller.Update(tt.target, tt.current, 1*time.Second)
+			controller.UpdateWithFeedforward(tt.target, tt.current, tt.feedforward, 1*time.Second)
 
 			count, err := testutil.GatherAndCount(registry, tt.name)
 			if err != nil {
@@ -63,13 +122,65 @@ func TestMetrics_Update(t *testing.T) {
 				t.Fatalf("expected metric %q to be registered", tt.name)
 			}
 			checkLabelValue(t, registry, tt.name, nameLabel, t.Name())
-			if got, want := tt.value(controller.metrics), tt.want; got != want {
+			if got, want := tt.value(controller.metrics.(*metrics)), tt.want; got != want {
 				t.Errorf("got %v, want: %v", got, want)
 			}
 		})
 	}
 }
 
+func TestMetrics_SaturatedSeconds(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	controller, err := New(
+		WithOutputLimit(-1.0, 1.0),
+		WithPrometheusMetrics(t.Name(), registry),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	controller.Update(5, 0, 2*time.Second)
+	controller.Update(5, 0, 1*time.Second)
+	controller.Update(0, 0, 1*time.Second)
+	controller.Update(-5, 0, 500*time.Millisecond)
+
+	saturated := controller.metrics.(*metrics).saturatedSeconds
+	if got, want := testutil.ToFloat64(saturated.WithLabelValues(t.Name(), "upper")), 3.0; got != want {
+		t.Errorf("got %v, want: %v", got, want)
+	}
+	if got, want := testutil.ToFloat64(saturated.WithLabelValues(t.Name(), "lower")), 0.5; got != want {
+		t.Errorf("got %v, want: %v", got, want)
+	}
+}
+
+func TestMetrics_TimeStepSeconds(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	controller, err := New(WithPrometheusMetrics(t.Name(), registry))
+	if err != nil {
+		t.Fatal(err)
+	}
+	controller.Update(5, 0, 100*time.Millisecond)
+	controller.Update(5, 0, 300*time.Millisecond)
+
+	mfs, err := registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, mf := range mfs {
+		if mf.GetName() != "pid_time_step_seconds" {
+			continue
+		}
+		h := mf.GetMetric()[0].GetHistogram()
+		if got, want := h.GetSampleCount(), uint64(2); got != want {
+			t.Errorf("got sample count %v, want: %v", got, want)
+		}
+		if got, want := h.GetSampleSum(), 0.4; math.Abs(got-want) > 1e-9 {
+			t.Errorf("got sample sum %v, want: %v", got, want)
+		}
+		return
+	}
+	t.Fatal("expected metric pid_time_step_seconds to be registered")
+}
+
 func TestReusePrometheusRegistry(t *testing.T) {
 	registry := prometheus.NewRegistry()
 	a, err := New(WithPrometheusMetrics("a", registry))
@@ -88,6 +199,173 @@ func TestReusePrometheusRegistry(t *testing.T) {
 	checkLabelValue(t, registry, "pid_control_signal", "name", "b")
 }
 
+func TestMetrics_Options(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	controller, err := New(WithPrometheusMetrics(t.Name(), registry,
+		WithMetricsNamespace("acme"),
+		WithMetricsSubsystem("autoscaler"),
+		WithMetricsConstLabels(prometheus.Labels{"service": "api"}),
+		WithMetricsHelp("control_signal", "Number of replicas."),
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+	controller.Update(5, 1, 1*time.Second)
+
+	const metric = "acme_autoscaler_control_signal"
+	checkLabelValue(t, registry, metric, nameLabel, t.Name())
+	checkLabelValue(t, registry, metric, "service", "api")
+
+	mfs, err := registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, mf := range mfs {
+		if mf.GetName() == metric {
+			if got, want := mf.GetHelp(), "Number of replicas."; got != want {
+				t.Errorf("got help %q, want: %q", got, want)
+			}
+			return
+		}
+	}
+	t.Fatalf("expected metric %q to be registered", metric)
+}
+
+func TestMetrics_ConstLabelsRegistration(t *testing.T) {
+	tests := []struct {
+		name    string
+		a       prometheus.Labels
+		b       prometheus.Labels
+		wantErr bool
+	}{
+		{
+			name: "same-labels",
+			a:    prometheus.Labels{"service": "api"},
+			b:    prometheus.Labels{"service": "api"},
+		},
+		{
+			name: "different-label-values",
+			a:    prometheus.Labels{"service": "api"},
+			b:    prometheus.Labels{"service": "worker"},
+		},
+		{
+			name:    "different-label-names",
+			a:       prometheus.Labels{"service": "api"},
+			b:       prometheus.Labels{"region": "eu"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := prometheus.NewRegistry()
+			a, err := New(WithPrometheusMetrics("a", registry, WithMetricsConstLabels(tt.a)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			b, err := New(WithPrometheusMetrics("b", registry, WithMetricsConstLabels(tt.b)))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			a.Update(7, 4, 1*time.Second)
+			b.Update(2, 3, 1*time.Second)
+
+			checkLabelValue(t, registry, "pid_control_signal", nameLabel, "a")
+			checkLabelValue(t, registry, "pid_control_signal", nameLabel, "b")
+		})
+	}
+}
+
+func TestMetrics_InvalidOptions(t *testing.T) {
+	tests := []struct {
+		name string
+		opt  MetricsOption
+	}{
+		{
+			name: "reserved-const-label",
+			opt:  WithMetricsConstLabels(prometheus.Labels{nameLabel: "a"}),
+		},
+		{
+			name: "help-of-unknown-metric",
+			opt:  WithMetricsHelp("unknown", "Unknown."),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := New(WithPrometheusMetrics(t.Name(), prometheus.NewRegistry(), tt.opt)); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
+
+func TestController_CloseChurn(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	static, err := New(WithPrometheusMetrics("static", registry))
+	if err != nil {
+		t.Fatal(err)
+	}
+	static.Update(5, 1, 1*time.Second)
+
+	for i := range 5000 {
+		controller, err := New(
+			WithOutputLimit(-1.0, 1.0),
+			WithPrometheusMetrics(fmt.Sprintf("tenant-%d", i), registry),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Touch every series, including those with additional labels.
+		controller.Update(5, 1, 1*time.Second)
+		controller.Update(-5, 1, 1*time.Second)
+		controller.Update(5, 1, 0)
+		controller.Update(math.NaN(), 1, 1*time.Second)
+		if err := controller.Close(); err != nil {
+			t.Fatal(err)
+		}
+		// Updates after closing must not recreate the series.
+		controller.Update(5, 1, 1*time.Second)
+	}
+
+	mfs, err := registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mfs) == 0 {
+		t.Fatal("expected the series of the static controller")
+	}
+	for _, mf := range mfs {
+		for _, m := range mf.GetMetric() {
+			for _, l := range m.GetLabel() {
+				if l.GetName() == nameLabel && l.GetValue() != "static" {
+					t.Fatalf("got leaked series of metric %q with label %q", mf.GetName(), l.GetValue())
+				}
+			}
+		}
+	}
+	checkLabelValue(t, registry, "pid_control_signal", nameLabel, "static")
+}
+
+func TestController_CloseWithoutMetrics(t *testing.T) {
+	controller, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := controller.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := controller.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
 func checkLabelValue(
 	tb testing.TB,
 	registry *prometheus.Registry,
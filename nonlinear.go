@@ -0,0 +1,160 @@
+package pid
+
+import (
+	"fmt"
+	"math"
+)
+
+// DeadbandMode selects how the error is treated outside of the deadband, see
+// [WithDeadband].
+type DeadbandMode int
+
+const (
+	// DeadbandZero sets the error to zero within the deadband and passes it
+	// through unchanged outside of it. The error jumps by the width of the
+	// deadband at its edges.
+	DeadbandZero DeadbandMode = iota
+	// DeadbandShifted sets the error to zero within the deadband and shifts it
+	// towards zero by the width of the deadband outside of it, such that the
+	// error is continuous at the edges.
+	DeadbandShifted
+)
+
+// errorShaping is the nonlinearity applied to the error before it drives the
+// proportional, integral, and derivative terms.
+type errorShaping struct {
+	deadband     float64
+	deadbandMode DeadbandMode
+
+	// Either the full scale of the error-squared nonlinearity, or the width and
+	// gain of the gap, zero if unused.
+	errorSquaredScale float64
+	gap               float64
+	gapGain           float64
+
+	// Gains applied to positive and negative errors, one by default.
+	positiveErrorGain float64
+	negativeErrorGain float64
+}
+
+// shaping returns a copy of the configured error shaping for modification by
+// an option, such that the configuration of the controller remains unchanged
+// until it is applied.
+func (o *options) shaping() *errorShaping {
+	s := errorShaping{
+		positiveErrorGain: 1.0,
+		negativeErrorGain: 1.0,
+	}
+	if o.errorShaping != nil {
+		s = *o.errorShaping
+	}
+	o.errorShaping = &s
+	return o.errorShaping
+}
+
+// apply returns the shaped error. The deadband is applied first, followed by
+// the error-squared or gap nonlinearity and the per-side gains.
+func (s errorShaping) apply(controlError float64) float64 {
+	magnitude, sign := math.Abs(controlError), math.Copysign(1.0, controlError)
+	if magnitude <= s.deadband {
+		return 0.0
+	}
+	if s.deadbandMode == DeadbandShifted {
+		magnitude -= s.deadband
+	}
+	switch {
+	case s.errorSquaredScale != 0.0:
+		magnitude *= magnitude / s.errorSquaredScale
+	case magnitude <= s.gap:
+		magnitude *= s.gapGain
+	default:
+		// Keep the error continuous at the edge of the gap.
+		magnitude += s.gap * (s.gapGain - 1.0)
+	}
+	if sign > 0.0 {
+		return s.positiveErrorGain * magnitude
+	}
+	return -s.negativeErrorGain * magnitude
+}
+
+// WithDeadband ignores errors whose magnitude does not exceed the deadband.
+// Small errors, such as measurement noise around the target, otherwise cause
+// the control signal to change constantly, wearing out actuators or causing
+// churn. Within the deadband the integral holds, which leaves a steady-state
+// error of up to the deadband.
+//
+// The deadband applies to the error that drives the proportional, integral,
+// and derivative terms, see [DeadbandMode] for the available modes. It does
+// not apply to the measurement differentiated by [WithDerivativeOnMeasurement].
+func WithDeadband(deadband float64, mode DeadbandMode) Option {
+	return func(o *options) error {
+		if deadband < 0.0 {
+			return fmt.Errorf("deadband: deadband must not be negative, got: %v", deadband)
+		}
+		switch mode {
+		case DeadbandZero, DeadbandShifted:
+		default:
+			return fmt.Errorf("deadband: unknown mode: %d", mode)
+		}
+		s := o.shaping()
+		s.deadband = deadband
+		s.deadbandMode = mode
+		return nil
+	}
+}
+
+// WithErrorSquared squares the error while retaining its sign, scaled such
+// that the error is unchanged at the given full scale. The controller responds
+// gently to small errors and aggressively to large ones, which suits processes
+// where small deviations are acceptable, such as buffer levels. It replaces a
+// gap configured with [WithGapGain].
+func WithErrorSquared(fullScale float64) Option {
+	return func(o *options) error {
+		if fullScale <= 0.0 {
+			return fmt.Errorf("error squared: full scale must be positive, got: %v", fullScale)
+		}
+		s := o.shaping()
+		s.errorSquaredScale = fullScale
+		s.gap = 0.0
+		s.gapGain = 0.0
+		return nil
+	}
+}
+
+// WithGapGain scales errors whose magnitude is within the gap by the given
+// gain, typically below one, to respond less to errors close to the target.
+// Outside of the gap the error changes at the full gain, offset such that it
+// is continuous at the edge of the gap. It replaces the nonlinearity
+// configured with [WithErrorSquared].
+func WithGapGain(gap, gain float64) Option {
+	return func(o *options) error {
+		if gap < 0.0 {
+			return fmt.Errorf("gap gain: gap must not be negative, got: %v", gap)
+		}
+		if gain < 0.0 {
+			return fmt.Errorf("gap gain: gain must not be negative, got: %v", gain)
+		}
+		s := o.shaping()
+		s.gap = gap
+		s.gapGain = gain
+		s.errorSquaredScale = 0.0
+		return nil
+	}
+}
+
+// WithAsymmetricGain scales positive and negative errors by separate gains.
+// This accounts for processes that respond differently depending on the
+// direction, such as heating and cooling, or scaling up and down where
+// releasing capacity should happen more cautiously than acquiring it. Both
+// gains default to one.
+func WithAsymmetricGain(positiveErrorGain, negativeErrorGain float64) Option {
+	return func(o *options) error {
+		if positiveErrorGain < 0.0 || negativeErrorGain < 0.0 {
+			return fmt.Errorf("asymmetric gain: gains must not be negative, got: %v, %v", positiveErrorGain, negativeErrorGain)
+		}
+		s := o.shaping()
+		s.positiveErrorGain = positiveErrorGain
+		s.negativeErrorGain = negativeErrorGain
+		return nil
+	}
+}
@@ -0,0 +1,178 @@
+package pid
+
+import (
+	"testing"
+	"time"
+)
+
+func TestController_ErrorShaping(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    []Option
+		current float64
+		want    float64
+	}{
+		{
+			name:    "no-shaping",
+			current: -3,
+			want:    3,
+		},
+		{
+			name:    "zero-deadband-within",
+			opts:    []Option{WithDeadband(1, DeadbandZero)},
+			current: 0.5,
+			want:    0,
+		},
+		{
+			name:    "zero-deadband-outside",
+			opts:    []Option{WithDeadband(1, DeadbandZero)},
+			current: -3,
+			want:    3,
+		},
+		{
+			name:    "shifted-deadband-positive-error",
+			opts:    []Option{WithDeadband(1, DeadbandShifted)},
+			current: -3,
+			want:    2,
+		},
+		{
+			name:    "shifted-deadband-negative-error",
+			opts:    []Option{WithDeadband(1, DeadbandShifted)},
+			current: 3,
+			want:    -2,
+		},
+		{
+			name:    "error-squared-below-full-scale",
+			opts:    []Option{WithErrorSquared(4)},
+			current: -2,
+			want:    1,
+		},
+		{
+			name:    "error-squared-retains-sign",
+			opts:    []Option{WithErrorSquared(4)},
+			current: 8,
+			want:    -16,
+		},
+		{
+			name:    "within-gap",
+			opts:    []Option{WithGapGain(2, 0.5)},
+			current: -1,
+			want:    0.5,
+		},
+		{
+			name:    "outside-gap",
+			opts:    []Option{WithGapGain(2, 0.5)},
+			current: 4,
+			want:    -3,
+		},
+		{
+			name:    "gap-replaces-error-squared",
+			opts:    []Option{WithErrorSquared(4), WithGapGain(2, 0.5)},
+			current: 4,
+			want:    -3,
+		},
+		{
+			name:    "asymmetric-gain-positive-error",
+			opts:    []Option{WithAsymmetricGain(2, 0.5)},
+			current: -3,
+			want:    6,
+		},
+		{
+			name:    "asymmetric-gain-negative-error",
+			opts:    []Option{WithAsymmetricGain(2, 0.5)},
+			current: 4,
+			want:    -2,
+		},
+		{
+			name:    "shifted-deadband-with-asymmetric-gain",
+			opts:    []Option{WithDeadband(1, DeadbandShifted), WithAsymmetricGain(2, 1)},
+			current: -3,
+			want:    4,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pid, err := New(tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := pid.Update(0, tt.current, 1*time.Second); got != tt.want {
+				t.Errorf("got %v, want: %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithDeadband_IntegralHolds(t *testing.T) {
+	pid, err := New(
+		WithProportionalGain(0.0),
+		WithIntegralGain(1.0),
+		WithDeadband(0.5, DeadbandZero),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := pid.Update(10, 8, 1*time.Second)
+
+	// Noise around the target must not change the control signal.
+	for _, current := range []float64{9.8, 10.3, 9.6, 10.1} {
+		if got := pid.Update(10, current, 1*time.Second); got != want {
+			t.Errorf("got %v, want: %v", got, want)
+		}
+	}
+}
+
+func TestErrorShaping_InvalidOptions(t *testing.T) {
+	tests := []struct {
+		name string
+		opt  Option
+	}{
+		{
+			name: "negative-deadband",
+			opt:  WithDeadband(-1, DeadbandZero),
+		},
+		{
+			name: "unknown-deadband-mode",
+			opt:  WithDeadband(1, DeadbandMode(-1)),
+		},
+		{
+			name: "non-positive-error-squared-full-scale",
+			opt:  WithErrorSquared(0),
+		},
+		{
+			name: "negative-gap",
+			opt:  WithGapGain(-1, 0.5),
+		},
+		{
+			name: "negative-gap-gain",
+			opt:  WithGapGain(1, -0.5),
+		},
+		{
+			name: "negative-asymmetric-gain",
+			opt:  WithAsymmetricGain(1, -1),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := New(tt.opt); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
+
+func TestController_ReconfigureErrorShapingOnError(t *testing.T) {
+	pid, err := New(WithDeadband(1, DeadbandZero))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = pid.Reconfigure(WithDeadband(2, DeadbandShifted), WithGapGain(-1, 0.5))
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if got, want := *pid.errorShaping, (errorShaping{deadband: 1, positiveErrorGain: 1, negativeErrorGain: 1}); got != want {
+		t.Errorf("got %+v, want: %+v", got, want)
+	}
+}
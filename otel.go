@@ -0,0 +1,145 @@
+package pid
+
+import (
+	"context"
+	"errors"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// otelMetrics is the OpenTelemetry [recorder]. It emits the same signals as
+// the Prometheus recorder, named with the "pid." prefix and without the unit
+// suffixes, which OpenTelemetry carries as metadata instead.
+type otelMetrics struct {
+	updates           metric.Int64Counter
+	rejected          metric.Int64Counter
+	target            metric.Float64Gauge
+	current           metric.Float64Gauge
+	controlSignal     metric.Float64Gauge
+	feedforward       metric.Float64Gauge
+	controlError      metric.Float64Gauge
+	proportionalTerm  metric.Float64Gauge
+	integralTerm      metric.Float64Gauge
+	derivativeTerm    metric.Float64Gauge
+	integral          metric.Float64Gauge
+	unsaturatedOutput metric.Float64Gauge
+	saturated         metric.Float64Counter
+	timeStep          metric.Float64Histogram
+
+	// Change of the control signal, only recorded by [VelocityController].
+	controlSignalIncrement metric.Float64Gauge
+
+	attrs []attribute.KeyValue
+	set   metric.MeasurementOption
+}
+
+func newOtelMetrics(meter metric.Meter, attrs []attribute.KeyValue) (*otelMetrics, error) {
+	m := &otelMetrics{
+		attrs: attrs,
+		set:   metric.WithAttributeSet(attribute.NewSet(attrs...)),
+	}
+	var errs []error
+	gauge := func(name string) metric.Float64Gauge {
+		g, err := meter.Float64Gauge("pid."+name, metric.WithDescription(defaultHelp[name]))
+		errs = append(errs, err)
+		return g
+	}
+	m.target = gauge("target")
+	m.current = gauge("current")
+	m.controlSignal = gauge("control_signal")
+	m.controlSignalIncrement = gauge("control_signal_increment")
+	m.feedforward = gauge("feedforward")
+	m.controlError = gauge("error")
+	m.proportionalTerm = gauge("proportional_term")
+	m.integralTerm = gauge("integral_term")
+	m.derivativeTerm = gauge("derivative_term")
+	m.integral = gauge("integral")
+	m.unsaturatedOutput = gauge("unsaturated_output")
+
+	var err error
+	m.updates, err = meter.Int64Counter("pid.updates",
+		metric.WithDescription(defaultHelp["updates_total"]),
+	)
+	errs = append(errs, err)
+	m.rejected, err = meter.Int64Counter("pid.rejected_updates",
+		metric.WithDescription(defaultHelp["rejected_updates_total"]),
+	)
+	errs = append(errs, err)
+	m.saturated, err = meter.Float64Counter("pid.saturated",
+		metric.WithDescription(defaultHelp["saturated_seconds_total"]),
+		metric.WithUnit("s"),
+	)
+	errs = append(errs, err)
+	m.timeStep, err = meter.Float64Histogram("pid.time_step",
+		metric.WithDescription(defaultHelp["time_step_seconds"]),
+		metric.WithUnit("s"),
+		metric.WithExplicitBucketBoundaries(timeStepBuckets...),
+	)
+	errs = append(errs, err)
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// with returns the attributes of the controller with the given attribute.
+func (m *otelMetrics) with(kv attribute.KeyValue) metric.MeasurementOption {
+	attrs := make([]attribute.KeyValue, 0, len(m.attrs)+1)
+	attrs = append(attrs, m.attrs...)
+	return metric.WithAttributeSet(attribute.NewSet(append(attrs, kv)...))
+}
+
+func (m *otelMetrics) update(target, current, feedforward, step float64) {
+	ctx := context.Background()
+	m.updates.Add(ctx, 1, m.set)
+	m.target.Record(ctx, target, m.set)
+	m.current.Record(ctx, current, m.set)
+	m.feedforward.Record(ctx, feedforward, m.set)
+	m.timeStep.Record(ctx, step, m.set)
+}
+
+func (m *otelMetrics) reject(err error) {
+	m.rejected.Add(context.Background(), 1, m.with(attribute.String(reasonLabel, rejectReason(err))))
+}
+
+func (m *otelMetrics) output(controlSignal float64) {
+	m.controlSignal.Record(context.Background(), controlSignal, m.set)
+}
+
+func (m *otelMetrics) increment(increment float64) {
+	m.controlSignalIncrement.Record(context.Background(), increment, m.set)
+}
+
+func (m *otelMetrics) terms(t terms) {
+	ctx := context.Background()
+	m.controlError.Record(ctx, t.controlError, m.set)
+	m.proportionalTerm.Record(ctx, t.proportional, m.set)
+	m.integralTerm.Record(ctx, t.integral, m.set)
+	m.derivativeTerm.Record(ctx, t.derivative, m.set)
+	m.integral.Record(ctx, t.integralState, m.set)
+	m.unsaturatedOutput.Record(ctx, t.unsaturatedOutput, m.set)
+}
+
+func (m *otelMetrics) saturate(bound string, seconds float64) {
+	m.saturated.Add(context.Background(), seconds, m.with(attribute.String(boundLabel, bound)))
+}
+
+// close is a no-op: the OpenTelemetry API offers no way to remove the series
+// of an attribute set, the SDK bounds their number by its cardinality limit.
+func (m *otelMetrics) close() {}
+
+// WithOpenTelemetryMetrics enables OpenTelemetry instrumentation for the
+// controller, emitting the same signals as [WithPrometheusMetrics] through
+// instruments created by the provided meter. The given attributes are added to
+// every measurement to differentiate between multiple Controller instances.
+func WithOpenTelemetryMetrics(meter metric.Meter, attrs ...attribute.KeyValue) Option {
+	return func(o *options) error {
+		m, err := newOtelMetrics(meter, attrs)
+		if err != nil {
+			return err
+		}
+		o.metrics = m
+		return nil
+	}
+}
@@ -0,0 +1,248 @@
+package pid
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestOpenTelemetryMetrics_Update(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        []Option
+		target      float64
+		current     float64
+		feedforward float64
+		want        float64
+	}{
+		{
+			name:    "pid.updates",
+			target:  5.0,
+			current: 2.0,
+			want:    1.0,
+		},
+		{
+			name:    "pid.target",
+			target:  5.0,
+			current: 2.0,
+			want:    5.0,
+		},
+		{
+			name:    "pid.current",
+			target:  5.0,
+			current: 2.0,
+			want:    2.0,
+		},
+		{
+			name:    "pid.control_signal",
+			target:  5.0,
+			current: 1.0,
+			want:    4.0,
+		},
+		{
+			name:        "pid.feedforward",
+			target:      5.0,
+			current:     1.0,
+			feedforward: 3.0,
+			want:        3.0,
+		},
+		{
+			name:    "pid.error",
+			target:  5.0,
+			current: 1.0,
+			want:    4.0,
+		},
+		{
+			name:    "pid.proportional_term",
+			opts:    []Option{WithProportionalGain(2.0)},
+			target:  5.0,
+			current: 1.0,
+			want:    8.0,
+		},
+		{
+			name:    "pid.integral_term",
+			opts:    []Option{WithIntegralGain(0.5)},
+			target:  5.0,
+			current: 1.0,
+			want:    2.0,
+		},
+		{
+			name:    "pid.derivative_term",
+			opts:    []Option{WithDerivativeGain(0.25)},
+			target:  5.0,
+			current: 1.0,
+			want:    1.0,
+		},
+		{
+			name:    "pid.integral",
+			opts:    []Option{WithIntegralGain(0.5)},
+			target:  5.0,
+			current: 1.0,
+			want:    4.0,
+		},
+		{
+			name:    "pid.unsaturated_output",
+			opts:    []Option{WithOutputLimit(-2.0, 2.0)},
+			target:  5.0,
+			current: 1.0,
+			want:    4.0,
+		},
+		{
+			name:    "pid.saturated",
+			opts:    []Option{WithOutputLimit(-2.0, 2.0)},
+			target:  5.0,
+			current: 1.0,
+			want:    1.0,
+		},
+		{
+			name:    "pid.time_step",
+			target:  5.0,
+			current: 1.0,
+			want:    1.0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader := sdkmetric.NewManualReader()
+			meter := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)).Meter(t.Name())
+			attrs := []attribute.KeyValue{attribute.String(nameLabel, t.Name())}
+			controller, err := New(append(tt.opts, WithOpenTelemetryMetrics(meter, attrs...))...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			controller.UpdateWithFeedforward(tt.target, tt.current, tt.feedforward, 1*time.Second)
+
+			points := collect(t, reader, tt.name)
+			if len(points) != 1 {
+				t.Fatalf("got %d data points, want: 1", len(points))
+			}
+			if got, want := points[0].value, tt.want; got != want {
+				t.Errorf("got %v, want: %v", got, want)
+			}
+			if got, ok := points[0].attrs.Value(nameLabel); !ok || got.AsString() != t.Name() {
+				t.Errorf("got attribute %v, want: %v", got.AsString(), t.Name())
+			}
+		})
+	}
+}
+
+func TestOpenTelemetryMetrics_Attributes(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	meter := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)).Meter(t.Name())
+	controller, err := New(
+		WithOutputLimit(-1.0, 1.0),
+		WithOpenTelemetryMetrics(meter, attribute.String("service", "api")),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	controller.Update(5, 0, 2*time.Second)
+	controller.Update(-5, 0, 500*time.Millisecond)
+	controller.Update(5, 0, 0)
+	controller.Update(math.NaN(), 0, 1*time.Second)
+
+	for _, tt := range []struct {
+		metric string
+		key    string
+		value  string
+		want   float64
+	}{
+		{metric: "pid.saturated", key: boundLabel, value: "upper", want: 2.0},
+		{metric: "pid.saturated", key: boundLabel, value: "lower", want: 0.5},
+		{metric: "pid.rejected_updates", key: reasonLabel, value: "invalid_time_step", want: 1.0},
+		{metric: "pid.rejected_updates", key: reasonLabel, value: "invalid_input", want: 1.0},
+	} {
+		var found bool
+		for _, p := range collect(t, reader, tt.metric) {
+			if v, ok := p.attrs.Value(attribute.Key(tt.key)); !ok || v.AsString() != tt.value {
+				continue
+			}
+			found = true
+			if v, ok := p.attrs.Value("service"); !ok || v.AsString() != "api" {
+				t.Errorf("%s: got service %v, want: api", tt.metric, v.AsString())
+			}
+			if p.value != tt.want {
+				t.Errorf("%s{%s=%q}: got %v, want: %v", tt.metric, tt.key, tt.value, p.value, tt.want)
+			}
+		}
+		if !found {
+			t.Errorf("expected metric %q with attribute %q and value: %s", tt.metric, tt.key, tt.value)
+		}
+	}
+}
+
+func TestOpenTelemetryMetrics_Velocity(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	meter := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)).Meter(t.Name())
+	velocity, err := NewVelocity(WithOpenTelemetryMetrics(meter))
+	if err != nil {
+		t.Fatal(err)
+	}
+	velocity.Update(5, 1, 1*time.Second)
+	velocity.Update(5, 2, 1*time.Second)
+
+	for _, tt := range []struct {
+		metric string
+		want   float64
+	}{
+		{metric: "pid.control_signal", want: 3.0},
+		{metric: "pid.control_signal_increment", want: -1.0},
+	} {
+		points := collect(t, reader, tt.metric)
+		if len(points) != 1 {
+			t.Fatalf("%s: got %d data points, want: 1", tt.metric, len(points))
+		}
+		if got := points[0].value; got != tt.want {
+			t.Errorf("%s: got %v, want: %v", tt.metric, got, tt.want)
+		}
+	}
+}
+
+// point is a collected data point, for histograms the value is the count.
+type point struct {
+	attrs attribute.Set
+	value float64
+}
+
+// collect returns the data points of the metric with the given name.
+func collect(tb testing.TB, reader *sdkmetric.ManualReader, name string) []point {
+	tb.Helper()
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(tb.Context(), &rm); err != nil {
+		tb.Fatal(err)
+	}
+	var points []point
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name != name {
+				continue
+			}
+			switch data := m.Data.(type) {
+			case metricdata.Gauge[float64]:
+				for _, p := range data.DataPoints {
+					points = append(points, point{attrs: p.Attributes, value: p.Value})
+				}
+			case metricdata.Sum[float64]:
+				for _, p := range data.DataPoints {
+					points = append(points, point{attrs: p.Attributes, value: p.Value})
+				}
+			case metricdata.Sum[int64]:
+				for _, p := range data.DataPoints {
+					points = append(points, point{attrs: p.Attributes, value: float64(p.Value)})
+				}
+			case metricdata.Histogram[float64]:
+				for _, p := range data.DataPoints {
+					points = append(points, point{attrs: p.Attributes, value: float64(p.Count)})
+				}
+			default:
+				tb.Fatalf("unexpected data type %T of metric %q", m.Data, name)
+			}
+		}
+	}
+	return points
+}
@@ -3,6 +3,7 @@
 package pid
 
 import (
+	"fmt"
 	"math"
 	"time"
 
@@ -26,35 +27,140 @@ type Controller struct {
 	// derivativeGain reduces overshoot and oscillations but can amplify noise.
 	derivativeGain float64
 
+	// Setpoint weights (𝑏 and 𝑐) scale the target in the error of the
+	// proportional and derivative terms, turning the controller into a
+	// two-degree-of-freedom controller.
+	proportionalWeight float64
+	derivativeWeight   float64
+
+	// Optional nonlinearity applied to the error, such as a deadband.
+	errorShaping *errorShaping
+
 	prevControlError float64
+	prevMeasurement  float64
+	prevTarget       float64
 	integral         float64
 	derivative       float64
 
+	// Whether prevMeasurement holds a measurement, it is seeded by the first
+	// update such that the derivative on measurement does not kick.
+	measured bool
+
 	// Limits ensure that the controller operates within safe bounds and to
 	// prevent integral windup (overshoot, slow recovery, oscillation).
 	outputLimit   limit
 	integralLimit limit
 
+	// Optional limit of the rate at which the output changes per second, and
+	// the output of the previous step it is relative to.
+	outputRateLimit *limit
+	prevOutput      float64
+
+	// Anti-windup strategy and the tracking time constant (𝑇𝑡) used by
+	// back-calculation, zero selects a default derived from the gains.
+	antiWindup           AntiWindup
+	trackingTimeConstant float64
+
+	// Direction in which the integral contribution must not grow during the
+	// next update, set by [Cascade] while the inner controller saturates: 1
+	// blocks increases, -1 blocks decreases, and 0 blocks neither.
+	blockedIntegration int
+
 	lowPassFilterError      float64
 	lowPassFilterDerivative float64
 	trapezoidalIntegral     bool
-
-	metrics *metrics
+	derivativeOnMeasurement bool
+	maxTimeStep             time.Duration
+
+	// Clock of UpdateNow and the time of the previous update by time, zero
+	// before the first one.
+	clock      Clock
+	lastUpdate time.Time
+
+	// Gain schedule and the value of the external scheduling variable.
+	gainSchedule     *gainSchedule
+	schedulingSignal float64
+
+	// In manual mode the control signal is set by an operator. The integral
+	// tracks the manual output, and transfer indicates that the next update
+	// in automatic mode must back-initialize the integral.
+	manual       bool
+	manualOutput float64
+	transfer     bool
+
+	metrics recorder
 }
 
 // New constructs a [*Controller] configured by the provided options.
 // Reasonable defaults are used when options are omitted.
 func New(opts ...Option) (*Controller, error) {
-	cfg := options{
-		proportionalGain: 1.0,
-		integralGain:     0.0,
-		derivativeGain:   0.0,
-		outputLimit:      newLimit(math.Inf(-1), math.Inf(1)),
-	}
+	cfg := defaultOptions()
 	if err := WithOptions(opts...)(&cfg); err != nil {
 		return nil, err
 	}
 
+	c := &Controller{}
+	c.configure(cfg)
+	return c, nil
+}
+
+// Reconfigure applies the provided options to the [*Controller] at runtime,
+// on top of its current configuration. The accumulated state is retained and
+// the integral is rescaled such that the integral contribution remains
+// continuous across gain changes, avoiding a bump in the control signal. If
+// an option returns an error, the configuration remains unchanged.
+func (c *Controller) Reconfigure(opts ...Option) error {
+	cfg := c.options()
+	if err := WithOptions(opts...)(&cfg); err != nil {
+		return err
+	}
+	c.configure(cfg)
+	return nil
+}
+
+// SetGains changes the proportional, integral, and derivative gains at
+// runtime without a bump in the control signal, see [Controller.Reconfigure].
+func (c *Controller) SetGains(proportionalGain, integralGain, derivativeGain float64) {
+	cfg := c.options()
+	cfg.proportionalGain = proportionalGain
+	cfg.integralGain = integralGain
+	cfg.derivativeGain = derivativeGain
+	c.configure(cfg)
+}
+
+// options returns the current configuration of the [*Controller].
+func (c *Controller) options() options {
+	return options{
+		proportionalGain:        c.proportionalGain,
+		integralGain:            c.integralGain,
+		derivativeGain:          c.derivativeGain,
+		proportionalWeight:      c.proportionalWeight,
+		derivativeWeight:        c.derivativeWeight,
+		errorShaping:            c.errorShaping,
+		outputLimit:             c.outputLimit,
+		outputRateLimit:         c.outputRateLimit,
+		antiWindup:              c.antiWindup,
+		trackingTimeConstant:    c.trackingTimeConstant,
+		trapezoidalIntegral:     c.trapezoidalIntegral,
+		lowPassFilterError:      c.lowPassFilterError,
+		lowPassFilterDerivative: c.lowPassFilterDerivative,
+		derivativeOnMeasurement: c.derivativeOnMeasurement,
+		maxTimeStep:             c.maxTimeStep,
+		clock:                   c.clock,
+		gainSchedule:            c.gainSchedule,
+		metrics:                 c.metrics,
+	}
+}
+
+// configure applies the configuration to the [*Controller].
+func (c *Controller) configure(cfg options) {
+	// The integral contribution is 𝐾𝑖·integral, rescale the integral to keep it
+	// continuous when the integral gain changes. Without a prior integral gain
+	// there was no contribution, so the integral starts over.
+	if cfg.integralGain != c.integralGain && cfg.integralGain != 0.0 {
+		c.integral *= c.integralGain / cfg.integralGain
+	}
+
 	integralLimit := newLimit(math.Inf(-1), math.Inf(1))
 	if cfg.integralGain > 0.0 {
 		integralLimit = newLimit(
@@ -63,53 +169,239 @@ func New(opts ...Option) (*Controller, error) {
 		)
 	}
 
-	return &Controller{
-		proportionalGain:        cfg.proportionalGain,
-		integralGain:            cfg.integralGain,
-		derivativeGain:          cfg.derivativeGain,
-		outputLimit:             cfg.outputLimit,
-		integralLimit:           integralLimit,
-		trapezoidalIntegral:     cfg.trapezoidalIntegral,
-		lowPassFilterError:      cfg.lowPassFilterError,
-		lowPassFilterDerivative: cfg.lowPassFilterDerivative,
-		metrics:                 cfg.metrics,
-	}, nil
+	c.proportionalGain = cfg.proportionalGain
+	c.integralGain = cfg.integralGain
+	c.derivativeGain = cfg.derivativeGain
+	c.proportionalWeight = cfg.proportionalWeight
+	c.derivativeWeight = cfg.derivativeWeight
+	c.errorShaping = cfg.errorShaping
+	c.outputLimit = cfg.outputLimit
+	c.outputRateLimit = cfg.outputRateLimit
+	c.integralLimit = integralLimit
+	c.antiWindup = cfg.antiWindup
+	c.trackingTimeConstant = cfg.trackingTimeConstant
+	c.trapezoidalIntegral = cfg.trapezoidalIntegral
+	c.lowPassFilterError = cfg.lowPassFilterError
+	c.lowPassFilterDerivative = cfg.lowPassFilterDerivative
+	c.derivativeOnMeasurement = cfg.derivativeOnMeasurement
+	c.maxTimeStep = cfg.maxTimeStep
+	c.clock = cfg.clock
+	c.gainSchedule = cfg.gainSchedule
+	c.metrics = cfg.metrics
+
+	// The manual output must remain within the output limit.
+	c.manualOutput = c.outputLimit.apply(c.manualOutput)
 }
 
 // Update computes and returns the next control signal for the given target and
 // current measurement over the provided time step. Call Update once per
 // control loop iteration, passing the time elapsed since the previous call.
+//
+// An update with a time step that is not positive, or with an input that is
+// NaN or infinite, is rejected: the state remains unchanged and the previous
+// control signal is returned. Use [Controller.UpdateE] to detect rejections.
 func (c *Controller) Update(target, current float64, delta time.Duration) (controlSignal float64) {
-	step := float64(delta.Seconds())
+	return c.UpdateWithFeedforward(target, current, 0.0, delta)
+}
+
+// UpdateE is like [Controller.Update] but returns an error wrapping
+// [ErrInvalidTimeStep] or [ErrInvalidInput] if the update is rejected.
+func (c *Controller) UpdateE(target, current float64, delta time.Duration) (float64, error) {
+	return c.update(target, current, 0.0, delta)
+}
+
+// UpdateWithFeedforward is like [Controller.Update] but adds the feedforward
+// term to the control signal before the output limit is applied.
+//
+// Feedforward compensates known disturbances, such as the incoming request
+// rate, before they show up as error. The feedback terms only have to correct
+// what the feedforward term does not anticipate. The feedforward term is
+// accounted for by anti-windup and bumpless transfer.
+func (c *Controller) UpdateWithFeedforward(target, current, feedforward float64, delta time.Duration) (controlSignal float64) {
+	controlSignal, _ = c.update(target, current, feedforward, delta)
+	return controlSignal
+}
+
+// update computes the next control signal, see [Controller.UpdateWithFeedforward].
+func (c *Controller) update(target, current, feedforward float64, delta time.Duration) (controlSignal float64, err error) {
+	if err := validateUpdate(target, current, feedforward, delta); err != nil {
+		if c.metrics != nil {
+			c.metrics.reject(err)
+		}
+		if c.manual {
+			return c.manualOutput, err
+		}
+		// Before the first update, the previous output is zero, which the
+		// output limit may exclude.
+		return c.outputLimit.apply(c.prevOutput), err
+	}
+	step := capTimeStep(delta, c.maxTimeStep)
 
 	defer func() {
+		c.prevOutput = controlSignal
 		if c.metrics == nil {
 			return
 		}
-		c.metrics.controlSignal.With(c.metrics.labels).Set(controlSignal)
+		c.metrics.output(controlSignal)
 	}()
-	c.collectMetrics(target, current)
+	c.collectMetrics(target, current, feedforward, step)
+	prevProportionalGain, prevDerivativeGain := c.proportionalGain, c.derivativeGain
+	c.schedule(target, current)
 
 	// Calculate the error value as the difference between the target and current
 	// value. This time-dependent error drives the PID terms (P, I, and D).
 	controlError := target - current
+	if c.errorShaping != nil {
+		controlError = c.errorShaping.apply(controlError)
+	}
 	// Optionally apply a low-pass filter to reduce noise in the error signal.
 	if c.lowPassFilterError != 0.0 {
 		controlError = (controlError*step + c.prevControlError*c.lowPassFilterError) / (c.lowPassFilterError + step)
 	}
 
-	c.integral = c.integralLimit.apply(c.updateIntegral(controlError, step))
-	c.derivative = c.updateDerivative(controlError, step)
+	prevIntegral := c.integral
+	c.integral = c.updateIntegral(controlError, step)
+	if c.antiWindup == AntiWindupClamp {
+		c.integral = c.integralLimit.apply(c.integral)
+	}
+	if float64(c.blockedIntegration)*c.integralGain*(c.integral-prevIntegral) > 0.0 {
+		c.integral = prevIntegral
+	}
+	// Differentiating the error causes a spike (derivative kick) whenever the
+	// target changes. The target is constant in between, so differentiating the
+	// negated measurement yields the same derivative without the kick.
+	if c.derivativeOnMeasurement {
+		if !c.measured {
+			c.prevMeasurement = current
+			c.measured = true
+		}
+		measurement := current
+		if c.lowPassFilterError != 0.0 {
+			measurement = (current*step + c.prevMeasurement*c.lowPassFilterError) / (c.lowPassFilterError + step)
+		}
+		c.derivative = c.updateDerivative(c.prevMeasurement-measurement, step)
+		c.prevMeasurement = measurement
+	} else {
+		// The weighted derivative error is 𝑐·target - current, its change is the
+		// change of the error less the unweighted change of the target.
+		change := controlError - c.prevControlError + (c.derivativeWeight-1.0)*(target-c.prevTarget)
+		c.derivative = c.updateDerivative(change, step)
+	}
 
 	// Defer updating the previous control error until after computing the
 	// integral and derivative, both depend on the prior error value.
 	c.prevControlError = controlError
+	c.prevTarget = target
+
+	// The weighted proportional error is 𝑏·target - current.
+	proportionalError := controlError + (c.proportionalWeight-1.0)*target
 
-	output := c.proportionalGain*controlError + c.integralGain*c.integral + c.derivativeGain*c.derivative
+	proportional := c.proportionalGain * proportionalError
+	derivative := c.derivativeGain * c.derivative
+
+	// Absorb the change of the proportional and derivative terms caused by
+	// scheduled gains into the integral, such that the control signal does not
+	// bump at a nonzero error.
+	if c.gainSchedule != nil && c.integralGain != 0.0 {
+		c.integral += ((prevProportionalGain-c.proportionalGain)*proportionalError +
+			(prevDerivativeGain-c.derivativeGain)*c.derivative) / c.integralGain
+	}
+
+	// Back-initialize the integral such that the output matches the manual
+	// output. This keeps the integral consistent while in manual mode and makes
+	// the transfer back to automatic mode bumpless.
+	if c.manual || c.transfer {
+		c.transfer = false
+		if c.integralGain != 0.0 {
+			c.integral = (c.manualOutput - proportional - derivative - feedforward) / c.integralGain
+		}
+		if c.manual {
+			return c.manualOutput, nil
+		}
+		prevIntegral = c.integral
+	}
+
+	output := proportional + c.integralGain*c.integral + derivative + feedforward
 
 	// Limits ensure that the controller operates within safe bounds and to
 	// prevent integral windup (overshoot, slow recovery, oscillation).
-	return c.outputLimit.apply(output)
+	controlSignal, slewLimited := c.limitOutput(output, step)
+	if slewLimited && c.integralGain != 0.0 {
+		// The rate limit holds the control signal back regardless of the
+		// error. Back-initialize the integral to the control signal, such that
+		// it neither winds up nor lags behind while the output slews.
+		c.integral = (controlSignal - proportional - derivative - feedforward) / c.integralGain
+	} else {
+		c.reconcileIntegral(prevIntegral, output, controlSignal, step)
+	}
+	c.collectTermMetrics(controlError, proportional, derivative, output, step)
+	return controlSignal, nil
+}
+
+// limitOutput applies the output rate limit, if any, followed by the output
+// limit. It reports whether the output was limited by the rate limit.
+func (c *Controller) limitOutput(output, step float64) (float64, bool) {
+	var slewLimited bool
+	if c.outputRateLimit != nil {
+		limited := c.outputRateLimit.applyRate(output, c.prevOutput, step)
+		slewLimited = limited != output
+		output = limited
+	}
+	return c.outputLimit.apply(output), slewLimited
+}
+
+// Reset clears the accumulated state of the [*Controller] as if it had just
+// been constructed. The configuration and the mode are retained.
+func (c *Controller) Reset() {
+	c.prevControlError = 0.0
+	c.prevMeasurement = 0.0
+	c.measured = false
+	c.prevTarget = 0.0
+	c.integral = 0.0
+	c.derivative = 0.0
+	c.prevOutput = 0.0
+	c.lastUpdate = time.Time{}
+	c.transfer = false
+}
+
+// Close removes the metrics series of the [*Controller], if any, from the
+// registry it was registered with. Call Close before discarding a controller
+// whose metrics share a registry with long-lived controllers, otherwise its
+// series remain exported forever. Subsequent updates are no longer recorded,
+// which is all Close does for [WithOpenTelemetryMetrics] since OpenTelemetry
+// offers no way to remove series. Close always returns nil.
+func (c *Controller) Close() error {
+	if c.metrics == nil {
+		return nil
+	}
+	c.metrics.close()
+	c.metrics = nil
+	return nil
+}
+
+// SetManual switches the [*Controller] to manual mode where [Controller.Update]
+// returns the given output, clamped to the output limit, instead of computing
+// the control signal. Updates continue to track the error such that switching
+// back to automatic mode is bumpless.
+func (c *Controller) SetManual(output float64) {
+	c.manual = true
+	c.manualOutput = c.outputLimit.apply(output)
+}
+
+// SetAuto switches the [*Controller] back to automatic mode. The integral is
+// back-initialized such that the first update returns the last manual output.
+// Calling SetAuto in automatic mode has no effect.
+func (c *Controller) SetAuto() {
+	if !c.manual {
+		return
+	}
+	c.manual = false
+	c.transfer = true
+}
+
+// Manual reports whether the [*Controller] is in manual mode.
+func (c *Controller) Manual() bool {
+	return c.manual
 }
 
 // updateIntegral adds up past errors in every step to eliminate residual bias that
@@ -121,21 +413,56 @@ func (c *Controller) updateIntegral(controlError, step float64) float64 {
 	return c.integral + controlError*step
 }
 
-func (c *Controller) updateDerivative(controlError, step float64) float64 {
-	derivative := (controlError - c.prevControlError) / step
+// updateDerivative estimates the rate of change from the change of the
+// differentiated signal since the previous step.
+func (c *Controller) updateDerivative(change, step float64) float64 {
+	derivative := change / step
 	if c.lowPassFilterDerivative != 0.0 {
-		derivative = ((controlError - c.prevControlError) + c.lowPassFilterDerivative*c.derivative) / (step + c.lowPassFilterDerivative)
+		derivative = (change + c.lowPassFilterDerivative*c.derivative) / (step + c.lowPassFilterDerivative)
 	}
 	return derivative
 }
 
-func (c *Controller) collectMetrics(target, current float64) {
+func (c *Controller) collectMetrics(target, current, feedforward, step float64) {
+	if c.metrics == nil {
+		return
+	}
+	c.metrics.update(target, current, feedforward, step)
+}
+
+// collectTermMetrics records the contributions of the terms to the output and
+// the time spent saturated at either bound of the output limit.
+func (c *Controller) collectTermMetrics(controlError, proportional, derivative, output, step float64) {
 	if c.metrics == nil {
 		return
 	}
-	c.metrics.updatesTotal.With(c.metrics.labels).Inc()
-	c.metrics.target.With(c.metrics.labels).Set(target)
-	c.metrics.current.With(c.metrics.labels).Set(current)
+	c.metrics.terms(terms{
+		controlError:      controlError,
+		proportional:      proportional,
+		integral:          c.integralGain * c.integral,
+		derivative:        derivative,
+		integralState:     c.integral,
+		unsaturatedOutput: output,
+	})
+	switch {
+	case output > c.outputLimit.upper:
+		c.metrics.saturate("upper", step)
+	case output < c.outputLimit.lower:
+		c.metrics.saturate("lower", step)
+	}
+}
+
+// defaultOptions returns the configuration used when options are omitted.
+func defaultOptions() options {
+	return options{
+		proportionalGain:   1.0,
+		integralGain:       0.0,
+		derivativeGain:     0.0,
+		outputLimit:        newLimit(math.Inf(-1), math.Inf(1)),
+		proportionalWeight: 1.0,
+		derivativeWeight:   1.0,
+		clock:              systemClock{},
+	}
 }
 
 type options struct {
@@ -143,10 +470,20 @@ type options struct {
 	integralGain            float64
 	derivativeGain          float64
 	outputLimit             limit
+	outputRateLimit         *limit
+	proportionalWeight      float64
+	derivativeWeight        float64
+	errorShaping            *errorShaping
+	antiWindup              AntiWindup
+	trackingTimeConstant    float64
 	trapezoidalIntegral     bool
+	derivativeOnMeasurement bool
 	lowPassFilterError      float64
 	lowPassFilterDerivative float64
-	metrics                 *metrics
+	maxTimeStep             time.Duration
+	clock                   Clock
+	gainSchedule            *gainSchedule
+	metrics                 recorder
 }
 
 // Option is a functional option for flexible and extensible configuration of
@@ -204,6 +541,22 @@ func WithDerivativeGain(derivativeGain float64) Option {
 	}
 }
 
+// WithSetpointWeights configures the setpoint weights (𝑏 and 𝑐) of the
+// proportional and derivative terms, which then respond to 𝑏·target - current
+// and 𝑐·target - current respectively. The integral term always uses the
+// unweighted error to eliminate steady-state error.
+//
+// Weights below one soften the response to target changes without affecting
+// the response to disturbances, allowing aggressive tuning for disturbance
+// rejection while keeping target changes smooth. Both weights default to one.
+func WithSetpointWeights(proportionalWeight, derivativeWeight float64) Option {
+	return func(o *options) error {
+		o.proportionalWeight = proportionalWeight
+		o.derivativeWeight = derivativeWeight
+		return nil
+	}
+}
+
 // WithLowPassFilterError includes a low-pass filter with the specified time
 // constant which can be used to smooth out high-frequency changes. A large
 // value results in a slow response and more smoothing. A small value results
@@ -223,6 +576,55 @@ func WithOutputLimit(lower, upper float64) Option {
 	}
 }
 
+// WithOutputRateLimit limits how fast the control signal changes, in units
+// per second, based on the time step passed to [Controller.Update]. While
+// [WithOutputLimit] bounds the magnitude, a large error can otherwise still
+// swing the control signal from one bound to the other in a single step.
+//
+// While the control signal is rate-limited, the integral is back-initialized
+// to it regardless of the [AntiWindup] strategy, such that the integral
+// neither winds up nor lags behind the control signal.
+func WithOutputRateLimit(maxRisePerSecond, maxFallPerSecond float64) Option {
+	return func(o *options) error {
+		if maxRisePerSecond < 0.0 || maxFallPerSecond < 0.0 {
+			return fmt.Errorf("output rate limit: rates must not be negative, got: %v, %v", maxRisePerSecond, maxFallPerSecond)
+		}
+		l := newLimit(-maxFallPerSecond, maxRisePerSecond)
+		o.outputRateLimit = &l
+		return nil
+	}
+}
+
+// WithAntiWindup selects the strategy used to prevent integral windup while
+// the control signal is saturated by the output limit. See [AntiWindup] for
+// the available strategies.
+func WithAntiWindup(strategy AntiWindup) Option {
+	return func(o *options) error {
+		switch strategy {
+		case AntiWindupClamp, AntiWindupBackCalculation, AntiWindupConditionalIntegration:
+		default:
+			return fmt.Errorf("anti-windup: unknown strategy: %d", strategy)
+		}
+		o.antiWindup = strategy
+		return nil
+	}
+}
+
+// WithTrackingTimeConstant sets the tracking time constant (𝑇𝑡) which
+// determines how fast back-calculation resets the integral when the output
+// saturates. A small value resets the integral quickly. If omitted, it
+// defaults to the geometric mean of the integral and derivative time
+// constants.
+func WithTrackingTimeConstant(trackingTimeConstant float64) Option {
+	return func(o *options) error {
+		if trackingTimeConstant < 0.0 {
+			return fmt.Errorf("anti-windup: tracking time constant must not be negative, got: %v", trackingTimeConstant)
+		}
+		o.trackingTimeConstant = trackingTimeConstant
+		return nil
+	}
+}
+
 // WithTrapezoidalIntegral configures whether the [*Controller] should use the
 // trapezoidal method for the integral term.
 //
@@ -237,13 +639,36 @@ func WithTrapezoidalIntegral(enabled bool) Option {
 	}
 }
 
+// WithDerivativeOnMeasurement configures whether the [*Controller] computes the
+// derivative term from the measurement instead of the error.
+//
+// Differentiating the error causes a spike in the control signal, known as
+// derivative kick, whenever the target changes. While the target is constant
+// both produce the same result. The measurement is smoothed by the low-pass
+// filter configured through [WithLowPassFilterError].
+func WithDerivativeOnMeasurement(enabled bool) Option {
+	return func(o *options) error {
+		o.derivativeOnMeasurement = enabled
+		return nil
+	}
+}
+
 // WithPrometheusMetrics enables Prometheus instrumentation for the controller.
 // Metrics are registered with the provided registerer and use the given name
 // as a constant label value to differentiate between multiple Controller
-// instances.
-func WithPrometheusMetrics(name string, registerer prometheus.Registerer) Option {
+// instances. The metric names, help strings, and additional constant labels
+// are configured by the provided [MetricsOption] values.
+func WithPrometheusMetrics(name string, registerer prometheus.Registerer, opts ...MetricsOption) Option {
 	return func(o *options) error {
-		m, err := newMetrics(name, registerer)
+		cfg := metricsOptions{
+			namespace: "pid",
+		}
+		for _, opt := range opts {
+			if err := opt(&cfg); err != nil {
+				return err
+			}
+		}
+		m, err := newMetrics(name, registerer, cfg)
 		if err != nil {
 			return err
 		}
@@ -28,12 +28,17 @@ func TestController(t *testing.T) {
 			inputs:      []float64{7},
 			wantOutputs: []float64{1.5 * (10 - 7)},
 			wantController: &Controller{
-				proportionalGain: 1.5,
-				prevControlError: 3,
-				derivative:       3,
-				integral:         3,
-				outputLimit:      limit{lower: math.Inf(-1), upper: math.Inf(1)},
-				integralLimit:    limit{lower: math.Inf(-1), upper: math.Inf(1)},
+				proportionalWeight: 1,
+				derivativeWeight:   1,
+				proportionalGain:   1.5,
+				prevControlError:   3,
+				prevTarget:         10,
+				derivative:         3,
+				integral:           3,
+				prevOutput:         4.5,
+				outputLimit:        limit{lower: math.Inf(-1), upper: math.Inf(1)},
+				integralLimit:      limit{lower: math.Inf(-1), upper: math.Inf(1)},
+				clock:              systemClock{},
 			},
 		},
 		{
@@ -50,12 +55,17 @@ func TestController(t *testing.T) {
 				7.5,
 			},
 			wantController: &Controller{
-				integralGain:     1.5,
-				prevControlError: 2,
-				integral:         5,
-				derivative:       -1,
-				outputLimit:      limit{lower: math.Inf(-1), upper: math.Inf(1)},
-				integralLimit:    limit{lower: math.Inf(-1), upper: math.Inf(1)},
+				proportionalWeight: 1,
+				derivativeWeight:   1,
+				integralGain:       1.5,
+				prevControlError:   2,
+				prevTarget:         10,
+				integral:           5,
+				derivative:         -1,
+				prevOutput:         7.5,
+				outputLimit:        limit{lower: math.Inf(-1), upper: math.Inf(1)},
+				integralLimit:      limit{lower: math.Inf(-1), upper: math.Inf(1)},
+				clock:              systemClock{},
 			},
 		},
 		{
@@ -72,12 +82,17 @@ func TestController(t *testing.T) {
 				7.5,
 			},
 			wantController: &Controller{
-				derivativeGain:   1.5,
-				prevControlError: 8,
-				integral:         11,
-				derivative:       5,
-				outputLimit:      limit{lower: math.Inf(-1), upper: math.Inf(1)},
-				integralLimit:    limit{lower: math.Inf(-1), upper: math.Inf(1)},
+				proportionalWeight: 1,
+				derivativeWeight:   1,
+				derivativeGain:     1.5,
+				prevControlError:   8,
+				prevTarget:         10,
+				integral:           11,
+				derivative:         5,
+				prevOutput:         7.5,
+				outputLimit:        limit{lower: math.Inf(-1), upper: math.Inf(1)},
+				integralLimit:      limit{lower: math.Inf(-1), upper: math.Inf(1)},
+				clock:              systemClock{},
 			},
 		},
 		{
@@ -94,14 +109,19 @@ func TestController(t *testing.T) {
 				8.5,
 			},
 			wantController: &Controller{
-				proportionalGain: 2.0,
-				integralGain:     1.0,
-				derivativeGain:   0.5,
-				prevControlError: 2,
-				integral:         5,
-				derivative:       -1,
-				outputLimit:      limit{lower: math.Inf(-1), upper: math.Inf(1)},
-				integralLimit:    limit{lower: math.Inf(-1), upper: math.Inf(1)},
+				proportionalWeight: 1,
+				derivativeWeight:   1,
+				proportionalGain:   2.0,
+				integralGain:       1.0,
+				derivativeGain:     0.5,
+				prevControlError:   2,
+				prevTarget:         10,
+				integral:           5,
+				derivative:         -1,
+				prevOutput:         8.5,
+				outputLimit:        limit{lower: math.Inf(-1), upper: math.Inf(1)},
+				integralLimit:      limit{lower: math.Inf(-1), upper: math.Inf(1)},
+				clock:              systemClock{},
 			},
 		},
 		{
@@ -115,11 +135,15 @@ func TestController(t *testing.T) {
 			inputs:      []float64{0},
 			wantOutputs: []float64{0},
 			wantController: &Controller{
-				prevControlError: 10,
-				integral:         10,
-				derivative:       10,
-				outputLimit:      limit{lower: math.Inf(-1), upper: math.Inf(1)},
-				integralLimit:    limit{lower: math.Inf(-1), upper: math.Inf(1)},
+				proportionalWeight: 1,
+				derivativeWeight:   1,
+				prevControlError:   10,
+				prevTarget:         10,
+				integral:           10,
+				derivative:         10,
+				outputLimit:        limit{lower: math.Inf(-1), upper: math.Inf(1)},
+				integralLimit:      limit{lower: math.Inf(-1), upper: math.Inf(1)},
+				clock:              systemClock{},
 			},
 		},
 		{
@@ -134,12 +158,237 @@ func TestController(t *testing.T) {
 			inputs:      []float64{7},
 			wantOutputs: []float64{3},
 			wantController: &Controller{
-				proportionalGain: 1.5,
-				prevControlError: 3,
-				derivative:       3,
-				integral:         3,
-				outputLimit:      limit{lower: -3, upper: 3},
-				integralLimit:    limit{lower: math.Inf(-1), upper: math.Inf(1)},
+				proportionalWeight: 1,
+				derivativeWeight:   1,
+				proportionalGain:   1.5,
+				prevControlError:   3,
+				prevTarget:         10,
+				derivative:         3,
+				integral:           3,
+				prevOutput:         3,
+				outputLimit:        limit{lower: -3, upper: 3},
+				integralLimit:      limit{lower: math.Inf(-1), upper: math.Inf(1)},
+				clock:              systemClock{},
+			},
+		},
+		{
+			name: "clamp-anti-windup-bounds-integral-by-output-limit",
+			opts: []Option{
+				WithProportionalGain(1.0),
+				WithIntegralGain(1.0),
+				WithOutputLimit(-5, 5),
+			},
+			target:      10,
+			inputs:      []float64{7, 7},
+			wantOutputs: []float64{5, 5},
+			wantController: &Controller{
+				proportionalWeight: 1,
+				derivativeWeight:   1,
+				proportionalGain:   1.0,
+				integralGain:       1.0,
+				prevControlError:   3,
+				prevTarget:         10,
+				integral:           5,
+				prevOutput:         5,
+				outputLimit:        limit{lower: -5, upper: 5},
+				integralLimit:      limit{lower: -5, upper: 5},
+				clock:              systemClock{},
+			},
+		},
+		{
+			name: "back-calculation-anti-windup-tracks-saturated-output",
+			opts: []Option{
+				WithProportionalGain(1.0),
+				WithIntegralGain(1.0),
+				WithOutputLimit(-5, 5),
+				WithAntiWindup(AntiWindupBackCalculation),
+				WithTrackingTimeConstant(1.0),
+			},
+			target:      10,
+			inputs:      []float64{7, 7},
+			wantOutputs: []float64{5, 5},
+			wantController: &Controller{
+				proportionalWeight:   1,
+				derivativeWeight:     1,
+				proportionalGain:     1.0,
+				integralGain:         1.0,
+				prevControlError:     3,
+				prevTarget:           10,
+				integral:             2,
+				prevOutput:           5,
+				outputLimit:          limit{lower: -5, upper: 5},
+				integralLimit:        limit{lower: -5, upper: 5},
+				clock:                systemClock{},
+				antiWindup:           AntiWindupBackCalculation,
+				trackingTimeConstant: 1.0,
+			},
+		},
+		{
+			name: "conditional-integration-anti-windup-stops-integral-at-limit",
+			opts: []Option{
+				WithProportionalGain(1.0),
+				WithIntegralGain(1.0),
+				WithOutputLimit(-5, 5),
+				WithAntiWindup(AntiWindupConditionalIntegration),
+			},
+			target:      10,
+			inputs:      []float64{7, 7},
+			wantOutputs: []float64{5, 5},
+			wantController: &Controller{
+				proportionalWeight: 1,
+				derivativeWeight:   1,
+				proportionalGain:   1.0,
+				integralGain:       1.0,
+				prevControlError:   3,
+				prevTarget:         10,
+				integral:           2,
+				prevOutput:         5,
+				outputLimit:        limit{lower: -5, upper: 5},
+				integralLimit:      limit{lower: -5, upper: 5},
+				clock:              systemClock{},
+				antiWindup:         AntiWindupConditionalIntegration,
+			},
+		},
+		{
+			name: "conditional-integration-anti-windup-integrates-up-to-limit",
+			opts: []Option{
+				WithProportionalGain(0.0),
+				WithIntegralGain(1.0),
+				WithOutputLimit(-1, 1),
+				WithAntiWindup(AntiWindupConditionalIntegration),
+			},
+			target:      10,
+			inputs:      []float64{0, 0},
+			wantOutputs: []float64{1, 1},
+			wantController: &Controller{
+				proportionalWeight: 1,
+				derivativeWeight:   1,
+				integralGain:       1.0,
+				prevControlError:   10,
+				prevTarget:         10,
+				integral:           1,
+				prevOutput:         1,
+				outputLimit:        limit{lower: -1, upper: 1},
+				integralLimit:      limit{lower: -1, upper: 1},
+				clock:              systemClock{},
+				antiWindup:         AntiWindupConditionalIntegration,
+			},
+		},
+		{
+			name: "conditional-integration-anti-windup-follows-trapezoidal-increment",
+			opts: []Option{
+				WithProportionalGain(0.0),
+				WithIntegralGain(1.0),
+				WithOutputLimit(-5, 5),
+				WithAntiWindup(AntiWindupConditionalIntegration),
+				WithTrapezoidalIntegral(true),
+			},
+			target: 10,
+			// The error turns negative but the trapezoidal increment is still
+			// positive and pushes further into saturation.
+			inputs:      []float64{0, 0, 11},
+			wantOutputs: []float64{5, 5, 5},
+			wantController: &Controller{
+				proportionalWeight:  1,
+				derivativeWeight:    1,
+				integralGain:        1.0,
+				prevControlError:    -1,
+				prevTarget:          10,
+				integral:            5,
+				derivative:          -11,
+				prevOutput:          5,
+				outputLimit:         limit{lower: -5, upper: 5},
+				integralLimit:       limit{lower: -5, upper: 5},
+				clock:               systemClock{},
+				antiWindup:          AntiWindupConditionalIntegration,
+				trapezoidalIntegral: true,
+			},
+		},
+		{
+			name: "conditional-integration-anti-windup-freezes-integral-of-reverse-acting-controller",
+			opts: []Option{
+				WithProportionalGain(-1.0),
+				WithIntegralGain(-1.0),
+				WithOutputLimit(-5, 5),
+				WithAntiWindup(AntiWindupConditionalIntegration),
+			},
+			target:      0,
+			inputs:      []float64{10, 10},
+			wantOutputs: []float64{5, 5},
+			wantController: &Controller{
+				proportionalWeight: 1,
+				derivativeWeight:   1,
+				proportionalGain:   -1.0,
+				integralGain:       -1.0,
+				prevControlError:   -10,
+				integral:           0,
+				prevOutput:         5,
+				outputLimit:        limit{lower: -5, upper: 5},
+				integralLimit:      limit{lower: math.Inf(-1), upper: math.Inf(1)},
+				clock:              systemClock{},
+				antiWindup:         AntiWindupConditionalIntegration,
+			},
+		},
+		{
+			name: "derivative-on-measurement-responds-to-measurement-change",
+			opts: []Option{
+				WithProportionalGain(0.0),
+				WithIntegralGain(0.0),
+				WithDerivativeGain(1.5),
+				WithDerivativeOnMeasurement(true),
+			},
+			target: 10.0,
+			inputs: []float64{7, 2},
+			// The first update seeds the previous measurement, it must not
+			// differentiate the measurement from zero.
+			wantOutputs: []float64{
+				0,
+				7.5,
+			},
+			wantController: &Controller{
+				proportionalWeight:      1,
+				derivativeWeight:        1,
+				derivativeGain:          1.5,
+				prevControlError:        8,
+				prevTarget:              10,
+				prevMeasurement:         2,
+				integral:                11,
+				derivative:              5,
+				measured:                true,
+				prevOutput:              7.5,
+				outputLimit:             limit{lower: math.Inf(-1), upper: math.Inf(1)},
+				integralLimit:           limit{lower: math.Inf(-1), upper: math.Inf(1)},
+				clock:                   systemClock{},
+				derivativeOnMeasurement: true,
+			},
+		},
+		{
+			name: "setpoint-weights-scale-target-in-proportional-and-derivative-terms",
+			opts: []Option{
+				WithProportionalGain(1.0),
+				WithIntegralGain(0.0),
+				WithDerivativeGain(1.0),
+				WithSetpointWeights(0.5, 0.0),
+			},
+			target: 10.0,
+			inputs: []float64{7, 8},
+			wantOutputs: []float64{
+				(0.5*10 - 7) + (0*10 - 7),
+				(0.5*10 - 8) + (-8 - -7),
+			},
+			wantController: &Controller{
+				proportionalGain:   1.0,
+				derivativeGain:     1.0,
+				proportionalWeight: 0.5,
+				derivativeWeight:   0.0,
+				prevControlError:   2,
+				prevTarget:         10,
+				integral:           5,
+				derivative:         -1,
+				prevOutput:         -4,
+				outputLimit:        limit{lower: math.Inf(-1), upper: math.Inf(1)},
+				integralLimit:      limit{lower: math.Inf(-1), upper: math.Inf(1)},
+				clock:              systemClock{},
 			},
 		},
 	}
@@ -168,3 +417,376 @@ func TestController(t *testing.T) {
 		})
 	}
 }
+
+func TestController_DerivativeOnMeasurement(t *testing.T) {
+	tests := []struct {
+		name                    string
+		derivativeOnMeasurement bool
+		want                    float64
+	}{
+		{
+			name:                    "error-causes-derivative-kick",
+			derivativeOnMeasurement: false,
+			want:                    15,
+		},
+		{
+			name:                    "measurement-avoids-derivative-kick",
+			derivativeOnMeasurement: true,
+			want:                    0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pid, err := New(
+				WithProportionalGain(0.0),
+				WithDerivativeGain(1.5),
+				WithDerivativeOnMeasurement(tt.derivativeOnMeasurement),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			pid.Update(10, 5, 1*time.Second)
+			// The target changes while the measurement remains unchanged.
+			if got := pid.Update(20, 5, 1*time.Second); got != tt.want {
+				t.Errorf("got %v, want: %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestController_DerivativeOnMeasurementFirstUpdate(t *testing.T) {
+	pid, err := New(
+		WithProportionalGain(0.0),
+		WithDerivativeGain(1.0),
+		WithDerivativeOnMeasurement(true),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// At steady state, the first update must not differentiate the
+	// measurement from zero.
+	if got, want := pid.Update(500, 500, 1*time.Second), 0.0; got != want {
+		t.Errorf("got %v, want: %v", got, want)
+	}
+}
+
+func TestController_ManualMode(t *testing.T) {
+	pid, err := New(
+		WithProportionalGain(1.0),
+		WithIntegralGain(0.5),
+		WithDerivativeGain(0.25),
+		WithOutputLimit(-10, 10),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pid.Update(10, 2, 1*time.Second)
+	pid.Update(10, 4, 1*time.Second)
+
+	pid.SetManual(4)
+	for _, current := range []float64{5, 7, 6} {
+		if got, want := pid.Update(10, current, 1*time.Second), 4.0; got != want {
+			t.Errorf("got %v, want: %v", got, want)
+		}
+	}
+
+	// The first update in automatic mode must not cause a bump even though
+	// the measurement changed.
+	pid.SetAuto()
+	if got, want := pid.Update(10, 8, 1*time.Second), 4.0; math.Abs(got-want) > 1e-9 {
+		t.Errorf("got %v, want: %v", got, want)
+	}
+	if got, want := pid.Update(10, 8, 1*time.Second), 4.0; got == want {
+		t.Errorf("expected integral to resume, got: %v", got)
+	}
+}
+
+func TestController_SetManualClampsToOutputLimit(t *testing.T) {
+	pid, err := New(WithOutputLimit(-1, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pid.SetManual(5)
+	if got, want := pid.Update(10, 0, 1*time.Second), 1.0; got != want {
+		t.Errorf("got %v, want: %v", got, want)
+	}
+}
+
+func TestController_Reset(t *testing.T) {
+	pid, err := New(
+		WithProportionalGain(2.0),
+		WithIntegralGain(1.0),
+		WithDerivativeGain(0.5),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := pid.Update(10, 7, 1*time.Second)
+	pid.Update(10, 8, 1*time.Second)
+
+	pid.Reset()
+	if got := pid.Update(10, 7, 1*time.Second); got != want {
+		t.Errorf("got %v, want: %v", got, want)
+	}
+}
+
+func TestController_Reconfigure(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []Option
+	}{
+		{
+			name: "integral-gain-increases",
+			opts: []Option{WithIntegralGain(2.0)},
+		},
+		{
+			name: "integral-gain-decreases",
+			opts: []Option{WithIntegralGain(0.25)},
+		},
+		{
+			name: "standard-form",
+			opts: []Option{WithStandardForm(2.0, 4.0, 0.0)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pid, err := New(
+				WithProportionalGain(2.0),
+				WithIntegralGain(1.0),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			pid.Update(10, 7, 1*time.Second)
+			pid.Update(10, 8, 1*time.Second)
+
+			want := pid.integralGain * pid.integral
+			if err := pid.Reconfigure(tt.opts...); err != nil {
+				t.Fatal(err)
+			}
+			if got := pid.integralGain * pid.integral; math.Abs(got-want) > 1e-9 {
+				t.Errorf("got %v, want: %v", got, want)
+			}
+		})
+	}
+}
+
+func TestController_ReconfigureOutputLimit(t *testing.T) {
+	pid, err := New(WithIntegralGain(2.0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := pid.Reconfigure(WithOutputLimit(-4, 4)); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := pid.integralLimit, newLimit(-2, 2); got != want {
+		t.Errorf("got %v, want: %v", got, want)
+	}
+}
+
+func TestController_ReconfigureError(t *testing.T) {
+	pid, err := New(WithIntegralGain(1.0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := pid.options()
+	if err := pid.Reconfigure(
+		WithIntegralGain(2.0),
+		WithTrackingTimeConstant(-1),
+	); err == nil {
+		t.Fatal("expected error")
+	}
+	if diff := cmp.Diff(pid.options(), want, cmp.AllowUnexported(options{}, limit{})); diff != "" {
+		t.Errorf("diff: %s", diff)
+	}
+}
+
+func TestController_SetGains(t *testing.T) {
+	pid, err := New(
+		WithProportionalGain(1.0),
+		WithIntegralGain(1.0),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pid.Update(10, 7, 1*time.Second)
+	pid.Update(10, 7, 1*time.Second)
+
+	// Doubling the integral gain while the error is zero must not change the
+	// control signal.
+	want := pid.Update(10, 10, 1*time.Second)
+	pid.SetGains(1.0, 2.0, 0.0)
+	if got := pid.Update(10, 10, 1*time.Second); got != want {
+		t.Errorf("got %v, want: %v", got, want)
+	}
+}
+
+func TestController_UpdateWithFeedforward(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        []Option
+		feedforward float64
+		want        []float64
+	}{
+		{
+			name: "feedforward-adds-to-control-signal",
+			opts: []Option{
+				WithProportionalGain(1.0),
+				WithIntegralGain(1.0),
+			},
+			feedforward: 2.0,
+			want:        []float64{3 + 3 + 2, 3 + 6 + 2},
+		},
+		{
+			name: "feedforward-is-limited-by-output-limit",
+			opts: []Option{
+				WithProportionalGain(1.0),
+				WithOutputLimit(-4, 4),
+			},
+			feedforward: 2.0,
+			want:        []float64{4, 4},
+		},
+		{
+			name: "feedforward-is-accounted-for-in-anti-windup",
+			opts: []Option{
+				WithProportionalGain(1.0),
+				WithIntegralGain(1.0),
+				WithOutputLimit(-6, 6),
+				WithAntiWindup(AntiWindupBackCalculation),
+				WithTrackingTimeConstant(1.0),
+			},
+			feedforward: 2.0,
+			want:        []float64{6, 6},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pid, err := New(tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []float64
+			for range tt.want {
+				got = append(got, pid.UpdateWithFeedforward(10, 7, tt.feedforward, 1*time.Second))
+			}
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t.Errorf("diff: %s", diff)
+			}
+		})
+	}
+}
+
+func TestController_UpdateWithFeedforwardBumpless(t *testing.T) {
+	pid, err := New(
+		WithProportionalGain(1.0),
+		WithIntegralGain(1.0),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pid.SetManual(5)
+	pid.UpdateWithFeedforward(10, 7, 2, 1*time.Second)
+	pid.SetAuto()
+	if got, want := pid.UpdateWithFeedforward(10, 8, 4, 1*time.Second), 5.0; got != want {
+		t.Errorf("got %v, want: %v", got, want)
+	}
+}
+
+func TestController_OutputRateLimit(t *testing.T) {
+	pid, err := New(
+		WithProportionalGain(10.0),
+		WithOutputRateLimit(2, 1),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []float64
+	for _, current := range []float64{0, 0, 20} {
+		got = append(got, pid.Update(10, current, 1*time.Second))
+	}
+	got = append(got, pid.Update(10, 20, 500*time.Millisecond))
+	if diff := cmp.Diff(got, []float64{2, 4, 3, 2.5}); diff != "" {
+		t.Errorf("diff: %s", diff)
+	}
+}
+
+func TestController_OutputRateLimitAntiWindup(t *testing.T) {
+	tests := []struct {
+		name       string
+		antiWindup AntiWindup
+	}{
+		{
+			name:       "clamp",
+			antiWindup: AntiWindupClamp,
+		},
+		{
+			name:       "back-calculation",
+			antiWindup: AntiWindupBackCalculation,
+		},
+		{
+			name:       "conditional-integration",
+			antiWindup: AntiWindupConditionalIntegration,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pid, err := New(
+				WithProportionalGain(0.0),
+				WithIntegralGain(1.0),
+				WithOutputRateLimit(1, 1),
+				WithAntiWindup(tt.antiWindup),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []float64
+			for range 5 {
+				got = append(got, pid.Update(10, 0, 1*time.Second))
+			}
+			// The integral follows the output while it slews, such that the
+			// output holds once the error vanishes.
+			if got, want := pid.integral, 5.0; got != want {
+				t.Errorf("got integral %v, want: %v", got, want)
+			}
+			for range 2 {
+				got = append(got, pid.Update(10, 10, 1*time.Second))
+			}
+			if diff := cmp.Diff(got, []float64{1, 2, 3, 4, 5, 5, 5}); diff != "" {
+				t.Errorf("diff: %s", diff)
+			}
+		})
+	}
+}
+
+func TestNew_InvalidOptions(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []Option
+	}{
+		{
+			name: "unknown-anti-windup-strategy",
+			opts: []Option{WithAntiWindup(AntiWindup(-1))},
+		},
+		{
+			name: "negative-tracking-time-constant",
+			opts: []Option{WithTrackingTimeConstant(-1)},
+		},
+		{
+			name: "negative-output-rate-limit",
+			opts: []Option{WithOutputRateLimit(1, -1)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := New(tt.opts...); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package pid
+
+import (
+	"sync"
+	"time"
+)
+
+// SafeController is a [*Controller] that is safe for concurrent use by
+// multiple goroutines, for example when measurements are processed on one
+// goroutine while the controller is retuned from another.
+//
+// All methods are serialized by a mutex: a call that returns happens before
+// any call that starts afterwards, so every call observes the effects of all
+// calls that completed before it. Calls that overlap in time are applied in
+// some sequential order.
+type SafeController struct {
+	mu         sync.Mutex
+	controller *Controller
+}
+
+// NewSafe constructs a [*SafeController] configured by the provided options,
+// see [New].
+func NewSafe(opts ...Option) (*SafeController, error) {
+	controller, err := New(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &SafeController{
+		controller: controller,
+	}, nil
+}
+
+// Update calls [Controller.Update] while holding the lock.
+func (s *SafeController) Update(target, current float64, delta time.Duration) float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.controller.Update(target, current, delta)
+}
+
+// UpdateE calls [Controller.UpdateE] while holding the lock.
+func (s *SafeController) UpdateE(target, current float64, delta time.Duration) (float64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.controller.UpdateE(target, current, delta)
+}
+
+// UpdateAt calls [Controller.UpdateAt] while holding the lock.
+func (s *SafeController) UpdateAt(target, current float64, now time.Time) float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.controller.UpdateAt(target, current, now)
+}
+
+// UpdateNow calls [Controller.UpdateNow] while holding the lock.
+func (s *SafeController) UpdateNow(target, current float64) float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.controller.UpdateNow(target, current)
+}
+
+// UpdateWithFeedforward calls [Controller.UpdateWithFeedforward] while holding
+// the lock.
+func (s *SafeController) UpdateWithFeedforward(target, current, feedforward float64, delta time.Duration) float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.controller.UpdateWithFeedforward(target, current, feedforward, delta)
+}
+
+// Reconfigure calls [Controller.Reconfigure] while holding the lock.
+func (s *SafeController) Reconfigure(opts ...Option) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.controller.Reconfigure(opts...)
+}
+
+// SetGains calls [Controller.SetGains] while holding the lock.
+func (s *SafeController) SetGains(proportionalGain, integralGain, derivativeGain float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.controller.SetGains(proportionalGain, integralGain, derivativeGain)
+}
+
+// SetSchedulingSignal calls [Controller.SetSchedulingSignal] while holding the
+// lock.
+func (s *SafeController) SetSchedulingSignal(value float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.controller.SetSchedulingSignal(value)
+}
+
+// Reset calls [Controller.Reset] while holding the lock.
+func (s *SafeController) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.controller.Reset()
+}
+
+// SetManual calls [Controller.SetManual] while holding the lock.
+func (s *SafeController) SetManual(output float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.controller.SetManual(output)
+}
+
+// SetAuto calls [Controller.SetAuto] while holding the lock.
+func (s *SafeController) SetAuto() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.controller.SetAuto()
+}
+
+// Manual calls [Controller.Manual] while holding the lock.
+func (s *SafeController) Manual() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.controller.Manual()
+}
+
+// Snapshot calls [Controller.Snapshot] while holding the lock.
+func (s *SafeController) Snapshot() State {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.controller.Snapshot()
+}
+
+// Restore calls [Controller.Restore] while holding the lock.
+func (s *SafeController) Restore(state State) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.controller.Restore(state)
+}
+
+// Close calls [Controller.Close] while holding the lock.
+func (s *SafeController) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.controller.Close()
+}
@@ -0,0 +1,80 @@
+package pid
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSafeController(t *testing.T) {
+	opts := []Option{
+		WithProportionalGain(2.0),
+		WithIntegralGain(1.0),
+		WithDerivativeGain(0.5),
+	}
+	safe, err := NewSafe(opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	unsafe, err := New(opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, current := range []float64{7, 8, 9} {
+		got := safe.Update(10, current, 1*time.Second)
+		want := unsafe.Update(10, current, 1*time.Second)
+		if got != want {
+			t.Errorf("got %v, want: %v", got, want)
+		}
+	}
+}
+
+func TestSafeController_Concurrent(t *testing.T) {
+	safe, err := NewSafe(
+		WithProportionalGain(2.0),
+		WithIntegralGain(1.0),
+		WithDerivativeGain(0.5),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const iterations = 1000
+	var wg sync.WaitGroup
+	wg.Go(func() {
+		for i := range iterations {
+			safe.Update(10, float64(i%10), 10*time.Millisecond)
+		}
+	})
+	wg.Go(func() {
+		for i := range iterations {
+			if i%2 == 0 {
+				safe.SetManual(float64(i))
+			} else {
+				safe.SetAuto()
+			}
+		}
+	})
+	wg.Go(func() {
+		for range iterations {
+			// The gains may change in between, causing a mismatch.
+			if err := safe.Restore(safe.Snapshot()); err != nil && !errors.Is(err, ErrStateGains) {
+				t.Error(err)
+				return
+			}
+		}
+	})
+	wg.Go(func() {
+		for i := range iterations {
+			safe.SetGains(2.0, 1.0+float64(i%2), 0.5)
+		}
+	})
+	wg.Go(func() {
+		for range iterations {
+			safe.Reset()
+		}
+	})
+	wg.Wait()
+}
@@ -0,0 +1,136 @@
+package pid
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// SchedulingVariable selects the signal on which the gains of a [*Controller]
+// are scheduled, see [WithGainSchedule].
+type SchedulingVariable int
+
+const (
+	// ScheduleOnCurrent schedules the gains on the current measurement.
+	ScheduleOnCurrent SchedulingVariable = iota
+	// ScheduleOnTarget schedules the gains on the target.
+	ScheduleOnTarget
+	// ScheduleOnExternal schedules the gains on an external signal, such as
+	// the load, set with [Controller.SetSchedulingSignal].
+	ScheduleOnExternal
+)
+
+// Breakpoint defines the gains of a gain schedule at a value of the
+// scheduling variable.
+type Breakpoint struct {
+	Value            float64
+	ProportionalGain float64
+	IntegralGain     float64
+	DerivativeGain   float64
+}
+
+type gainSchedule struct {
+	variable    SchedulingVariable
+	breakpoints []Breakpoint
+}
+
+// gains returns the gains at the given value of the scheduling variable,
+// interpolated linearly between the surrounding breakpoints. Beyond the first
+// and last breakpoint the gains are held constant.
+func (s *gainSchedule) gains(value float64) (float64, float64, float64) {
+	first, last := s.breakpoints[0], s.breakpoints[len(s.breakpoints)-1]
+	if value <= first.Value {
+		return first.ProportionalGain, first.IntegralGain, first.DerivativeGain
+	}
+	if value >= last.Value {
+		return last.ProportionalGain, last.IntegralGain, last.DerivativeGain
+	}
+	i, _ := slices.BinarySearchFunc(s.breakpoints, value, func(b Breakpoint, value float64) int {
+		return cmp.Compare(b.Value, value)
+	})
+	lo, hi := s.breakpoints[i-1], s.breakpoints[i]
+	w := (value - lo.Value) / (hi.Value - lo.Value)
+	return lo.ProportionalGain + w*(hi.ProportionalGain-lo.ProportionalGain),
+		lo.IntegralGain + w*(hi.IntegralGain-lo.IntegralGain),
+		lo.DerivativeGain + w*(hi.DerivativeGain-lo.DerivativeGain)
+}
+
+// schedule updates the gains for the current value of the scheduling variable.
+// The integral is rescaled on every change and absorbs the change of the
+// proportional and derivative terms, see [Controller.update], such that the
+// transition between operating points is bumpless.
+func (c *Controller) schedule(target, current float64) {
+	if c.gainSchedule == nil {
+		return
+	}
+	value := current
+	switch c.gainSchedule.variable {
+	case ScheduleOnTarget:
+		value = target
+	case ScheduleOnExternal:
+		value = c.schedulingSignal
+	}
+	proportionalGain, integralGain, derivativeGain := c.gainSchedule.gains(value)
+	if proportionalGain == c.proportionalGain &&
+		integralGain == c.integralGain &&
+		derivativeGain == c.derivativeGain {
+		return
+	}
+	c.SetGains(proportionalGain, integralGain, derivativeGain)
+}
+
+// SetSchedulingSignal sets the value of the external scheduling variable used
+// by [ScheduleOnExternal]. The gains are updated on the next update.
+func (c *Controller) SetSchedulingSignal(value float64) {
+	c.schedulingSignal = value
+}
+
+// WithGainSchedule schedules the gains of the controller on the given
+// variable. The gains are interpolated linearly between the breakpoints and
+// held constant beyond the first and last breakpoint. A schedule accounts for
+// processes that behave differently depending on the operating point, such as
+// at low and high load.
+//
+// The scheduled gains take precedence over the gains configured by other
+// options. The integral gains must be nonzero and of the same sign, since the
+// integral keeps the control signal continuous when the gains change.
+func WithGainSchedule(variable SchedulingVariable, breakpoints ...Breakpoint) Option {
+	return func(o *options) error {
+		switch variable {
+		case ScheduleOnCurrent, ScheduleOnTarget, ScheduleOnExternal:
+		default:
+			return fmt.Errorf("gain schedule: unknown scheduling variable: %d", variable)
+		}
+		if len(breakpoints) == 0 {
+			return errors.New("gain schedule: no breakpoints")
+		}
+		sorted := slices.Clone(breakpoints)
+		slices.SortFunc(sorted, func(a, b Breakpoint) int {
+			return cmp.Compare(a.Value, b.Value)
+		})
+		for i := 1; i < len(sorted); i++ {
+			if sorted[i].Value == sorted[i-1].Value {
+				return fmt.Errorf("gain schedule: duplicate breakpoint: %v", sorted[i].Value)
+			}
+		}
+		// The integral absorbs the changes of the proportional and derivative
+		// terms, which requires an integral gain that is never zero, including
+		// in between breakpoints.
+		for _, b := range sorted {
+			if b.IntegralGain == 0.0 || (b.IntegralGain > 0.0) != (sorted[0].IntegralGain > 0.0) {
+				return fmt.Errorf("gain schedule: integral gains must be nonzero and of the same sign, got: %v at %v", b.IntegralGain, b.Value)
+			}
+		}
+		o.gainSchedule = &gainSchedule{
+			variable:    variable,
+			breakpoints: sorted,
+		}
+		// Start with the gains of the first breakpoint until the first update.
+		first := sorted[0]
+		o.proportionalGain = first.ProportionalGain
+		o.integralGain = first.IntegralGain
+		o.derivativeGain = first.DerivativeGain
+		return nil
+	}
+}
@@ -0,0 +1,173 @@
+package pid
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestWithGainSchedule(t *testing.T) {
+	breakpoints := []Breakpoint{
+		{Value: 100, ProportionalGain: 3, IntegralGain: 1.5, DerivativeGain: 0.5},
+		{Value: 0, ProportionalGain: 1, IntegralGain: 0.5, DerivativeGain: 0},
+	}
+
+	tests := []struct {
+		name     string
+		variable SchedulingVariable
+		target   float64
+		current  float64
+		signal   float64
+		want     options
+	}{
+		{
+			name:     "below-first-breakpoint",
+			variable: ScheduleOnCurrent,
+			current:  -10,
+			want:     options{proportionalGain: 1, integralGain: 0.5, derivativeGain: 0},
+		},
+		{
+			name:     "between-breakpoints",
+			variable: ScheduleOnCurrent,
+			current:  25,
+			want:     options{proportionalGain: 1.5, integralGain: 0.75, derivativeGain: 0.125},
+		},
+		{
+			name:     "above-last-breakpoint",
+			variable: ScheduleOnCurrent,
+			current:  200,
+			want:     options{proportionalGain: 3, integralGain: 1.5, derivativeGain: 0.5},
+		},
+		{
+			name:     "scheduled-on-target",
+			variable: ScheduleOnTarget,
+			target:   50,
+			current:  200,
+			want:     options{proportionalGain: 2, integralGain: 1, derivativeGain: 0.25},
+		},
+		{
+			name:     "scheduled-on-external-signal",
+			variable: ScheduleOnExternal,
+			current:  200,
+			signal:   75,
+			want:     options{proportionalGain: 2.5, integralGain: 1.25, derivativeGain: 0.375},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pid, err := New(WithGainSchedule(tt.variable, breakpoints...))
+			if err != nil {
+				t.Fatal(err)
+			}
+			pid.SetSchedulingSignal(tt.signal)
+			pid.Update(tt.target, tt.current, 1*time.Second)
+			checkGains(t, pid.options(), tt.want, 1e-9)
+		})
+	}
+}
+
+func TestWithGainSchedule_Bumpless(t *testing.T) {
+	pid, err := New(WithGainSchedule(
+		ScheduleOnExternal,
+		Breakpoint{Value: 0, ProportionalGain: 0, IntegralGain: 1},
+		Breakpoint{Value: 1, ProportionalGain: 0, IntegralGain: 4},
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pid.Update(10, 7, 1*time.Second)
+	want := pid.Update(10, 10, 1*time.Second)
+
+	// The operating point changes while the error is zero, a pure integral
+	// controller must hold its control signal.
+	pid.SetSchedulingSignal(1)
+	if got := pid.Update(10, 10, 1*time.Second); math.Abs(got-want) > 1e-9 {
+		t.Errorf("got %v, want: %v", got, want)
+	}
+	if got, want := pid.integralGain, 4.0; got != want {
+		t.Errorf("got integral gain %v, want: %v", got, want)
+	}
+}
+
+func TestWithGainSchedule_BumplessAtNonzeroError(t *testing.T) {
+	opts := []Option{
+		WithGainSchedule(
+			ScheduleOnExternal,
+			Breakpoint{Value: 0, ProportionalGain: 1, IntegralGain: 1, DerivativeGain: 1},
+			Breakpoint{Value: 1, ProportionalGain: 3, IntegralGain: 1, DerivativeGain: 4},
+		),
+	}
+	scheduled, err := New(opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reference, err := New(opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	scheduled.Update(10, 5, 1*time.Second)
+	reference.Update(10, 5, 1*time.Second)
+
+	// The operating point changes while the error and its derivative are
+	// nonzero, the proportional and derivative gains change but the control
+	// signal must continue as if they had not.
+	scheduled.SetSchedulingSignal(1)
+	want := reference.Update(10, 7, 1*time.Second)
+	if got := scheduled.Update(10, 7, 1*time.Second); math.Abs(got-want) > 1e-9 {
+		t.Errorf("got %v, want: %v", got, want)
+	}
+	if got, want := scheduled.proportionalGain, 3.0; got != want {
+		t.Errorf("got proportional gain %v, want: %v", got, want)
+	}
+}
+
+func TestWithGainSchedule_InvalidOptions(t *testing.T) {
+	tests := []struct {
+		name string
+		opt  Option
+	}{
+		{
+			name: "unknown-scheduling-variable",
+			opt:  WithGainSchedule(SchedulingVariable(-1), Breakpoint{}),
+		},
+		{
+			name: "no-breakpoints",
+			opt:  WithGainSchedule(ScheduleOnCurrent),
+		},
+		{
+			name: "duplicate-breakpoints",
+			opt: WithGainSchedule(
+				ScheduleOnCurrent,
+				Breakpoint{Value: 1, IntegralGain: 1},
+				Breakpoint{Value: 1, IntegralGain: 2},
+			),
+		},
+		{
+			// Without integral gain, the transition to and from the breakpoint
+			// would bump at a nonzero error.
+			name: "zero-integral-gain",
+			opt: WithGainSchedule(
+				ScheduleOnCurrent,
+				Breakpoint{Value: 0, ProportionalGain: 1, IntegralGain: 1},
+				Breakpoint{Value: 1, ProportionalGain: 1, IntegralGain: 0},
+			),
+		},
+		{
+			name: "integral-gains-of-different-signs",
+			opt: WithGainSchedule(
+				ScheduleOnCurrent,
+				Breakpoint{Value: 0, IntegralGain: 1},
+				Breakpoint{Value: 1, IntegralGain: -1},
+			),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := New(tt.opt); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package sim
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// ErrNoStepChange is returned by [Analyze] when the target does not differ
+// from the initial measurement, leaving nothing to measure the response by.
+var ErrNoStepChange = errors.New("analyze: target equals initial measurement")
+
+// Performance summarizes the quality of a closed-loop response to a change of
+// the target.
+type Performance struct {
+	// RiseTime is the time the measurement takes to go from 10% to 90% of the
+	// change towards the final target.
+	RiseTime time.Duration
+	// SettlingTime is the time after which the measurement stays within the
+	// settling band around the final target.
+	SettlingTime time.Duration
+	// Overshoot is the maximum excursion beyond the final target as a
+	// percentage of the change.
+	Overshoot float64
+	// SteadyStateError is the difference between the final target and the
+	// final measurement.
+	SteadyStateError float64
+	// IAE is the integral of the absolute error.
+	IAE float64
+	// ISE is the integral of the squared error, which penalizes large errors.
+	ISE float64
+	// ITAE is the integral of the time-weighted absolute error, which
+	// penalizes errors that persist.
+	ITAE float64
+	// TotalVariation is the sum of absolute changes of the control signal, a
+	// measure of actuator wear.
+	TotalVariation float64
+	// Settled reports whether the measurement entered the settling band and
+	// remained within it until the end of the trajectory.
+	Settled bool
+	// Risen reports whether the measurement reached 90% of the change.
+	Risen bool
+}
+
+// AnalyzeOption is a functional option for the configuration of [Analyze].
+type AnalyzeOption func(*analyzeOptions) error
+
+type analyzeOptions struct {
+	settlingBand float64
+}
+
+// WithSettlingBand sets the band around the final target, as a fraction of the
+// change, within which the measurement is considered settled. It defaults to
+// 0.02, that is 2%.
+func WithSettlingBand(band float64) AnalyzeOption {
+	return func(o *analyzeOptions) error {
+		if band <= 0.0 {
+			return fmt.Errorf("analyze: settling band must be positive, got: %v", band)
+		}
+		o.settlingBand = band
+		return nil
+	}
+}
+
+// Analyze computes the performance of the response recorded by the trajectory,
+// from the initial measurement towards the final target. Time is measured
+// relative to the first sample. The trajectory can be simulated by a
+// [*Stepper] or recorded from a running control loop.
+func Analyze(trajectory Trajectory, opts ...AnalyzeOption) (Performance, error) {
+	cfg := analyzeOptions{
+		settlingBand: 0.02,
+	}
+	for _, opt := range opts {
+		if err := opt(&cfg); err != nil {
+			return Performance{}, err
+		}
+	}
+	if len(trajectory) < 2 {
+		return Performance{}, fmt.Errorf("analyze: got %d samples, want at least: 2", len(trajectory))
+	}
+
+	first, last := trajectory[0], trajectory[len(trajectory)-1]
+	change := last.Target - first.Current
+	if change == 0.0 {
+		return Performance{}, ErrNoStepChange
+	}
+
+	var (
+		p         Performance
+		rise10    time.Duration
+		risen10   bool
+		unsettled = -1
+		peak      float64
+	)
+	for i, s := range trajectory {
+		elapsed := s.Time - first.Time
+		// The progress is the normalized measurement, 0 at the initial
+		// measurement and 1 at the final target.
+		progress := (s.Current - first.Current) / change
+		if !risen10 && progress >= 0.1 {
+			risen10 = true
+			rise10 = elapsed
+		}
+		if !p.Risen && progress >= 0.9 {
+			p.Risen = true
+			p.RiseTime = elapsed - rise10
+		}
+		peak = max(peak, progress-1.0)
+		if math.Abs(progress-1.0) > cfg.settlingBand {
+			unsettled = i
+		}
+
+		if i == 0 {
+			continue
+		}
+		prev := trajectory[i-1]
+		dt := (s.Time - prev.Time).Seconds()
+		controlError := math.Abs(prev.Target - prev.Current)
+		p.IAE += controlError * dt
+		p.ISE += controlError * controlError * dt
+		p.ITAE += (prev.Time - first.Time).Seconds() * controlError * dt
+		p.TotalVariation += math.Abs(s.ControlSignal - prev.ControlSignal)
+	}
+
+	p.Overshoot = 100.0 * peak
+	p.SteadyStateError = last.Target - last.Current
+	if unsettled < len(trajectory)-1 {
+		p.Settled = true
+		p.SettlingTime = trajectory[unsettled+1].Time - first.Time
+	}
+	return p, nil
+}
@@ -0,0 +1,153 @@
+package sim
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/konradreiche/pid"
+)
+
+func TestAnalyze(t *testing.T) {
+	currents := []float64{0, 5, 9, 11, 10.5, 10, 10, 10}
+	controlSignals := []float64{10, 5, 1, -1, -0.5, 0, 0, 0}
+	var trajectory Trajectory
+	for i := range currents {
+		trajectory = append(trajectory, Sample{
+			Time:          time.Duration(i) * time.Second,
+			Target:        10,
+			Current:       currents[i],
+			ControlSignal: controlSignals[i],
+		})
+	}
+
+	tests := []struct {
+		name string
+		opts []AnalyzeOption
+		want Performance
+	}{
+		{
+			name: "default-settling-band",
+			want: Performance{
+				RiseTime:       1 * time.Second,
+				SettlingTime:   5 * time.Second,
+				Overshoot:      10,
+				IAE:            10 + 5 + 1 + 1 + 0.5,
+				ISE:            100 + 25 + 1 + 1 + 0.25,
+				ITAE:           0*10 + 1*5 + 2*1 + 3*1 + 4*0.5,
+				TotalVariation: 5 + 4 + 2 + 0.5 + 0.5,
+				Settled:        true,
+				Risen:          true,
+			},
+		},
+		{
+			name: "wide-settling-band",
+			opts: []AnalyzeOption{WithSettlingBand(0.15)},
+			want: Performance{
+				RiseTime:       1 * time.Second,
+				SettlingTime:   2 * time.Second,
+				Overshoot:      10,
+				IAE:            10 + 5 + 1 + 1 + 0.5,
+				ISE:            100 + 25 + 1 + 1 + 0.25,
+				ITAE:           0*10 + 1*5 + 2*1 + 3*1 + 4*0.5,
+				TotalVariation: 5 + 4 + 2 + 0.5 + 0.5,
+				Settled:        true,
+				Risen:          true,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Analyze(trajectory, tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(got, tt.want, cmpopts.EquateApprox(0, 1e-9)); diff != "" {
+				t.Errorf("diff: %s", diff)
+			}
+		})
+	}
+}
+
+func TestAnalyze_Unsettled(t *testing.T) {
+	trajectory := Trajectory{
+		{Time: 0, Target: 10, Current: 0},
+		{Time: time.Second, Target: 10, Current: 5},
+		{Time: 2 * time.Second, Target: 10, Current: 8},
+	}
+	got, err := Analyze(trajectory)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Settled || got.Risen {
+		t.Errorf("got settled %v and risen %v, want: false", got.Settled, got.Risen)
+	}
+	if got, want := got.SteadyStateError, 2.0; got != want {
+		t.Errorf("got steady-state error %v, want: %v", got, want)
+	}
+}
+
+func TestAnalyze_InvalidInput(t *testing.T) {
+	tests := []struct {
+		name       string
+		trajectory Trajectory
+		opts       []AnalyzeOption
+		wantErr    error
+	}{
+		{
+			name:       "no-step-change",
+			trajectory: Trajectory{{Target: 1, Current: 1}, {Time: time.Second, Target: 1, Current: 1}},
+			wantErr:    ErrNoStepChange,
+		},
+		{
+			name:       "insufficient-samples",
+			trajectory: Trajectory{{Target: 1}},
+		},
+		{
+			name:       "invalid-settling-band",
+			trajectory: Trajectory{{Target: 1}, {Time: time.Second, Target: 1}},
+			opts:       []AnalyzeOption{WithSettlingBand(0)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Analyze(tt.trajectory, tt.opts...)
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got %v, want: %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestAnalyze_Regression demonstrates guarding the loop quality of a
+// controller configuration against regressions.
+func TestAnalyze_Regression(t *testing.T) {
+	model := pid.FOPDT{Gain: 1, TimeConstant: 5, DeadTime: 1}
+	controller, err := pid.New(
+		pid.WithSIMCMethod(model, model.DeadTime),
+		pid.WithOutputLimit(0, 100),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plant := NewFOPDT(model.Gain, model.TimeConstant, model.DeadTime)
+	trajectory := NewStepper(controller, plant, Constant(10), 100*time.Millisecond).Run(time.Minute)
+
+	got, err := Analyze(trajectory)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.Settled || got.SettlingTime > 15*time.Second {
+		t.Errorf("got settling time %v, want at most: 15s", got.SettlingTime)
+	}
+	if got.Overshoot > 10 {
+		t.Errorf("got overshoot %v%%, want at most: 10%%", got.Overshoot)
+	}
+}
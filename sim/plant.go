@@ -0,0 +1,271 @@
+package sim
+
+import (
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+// FirstOrder is a first-order process, such as a thermal mass: the output
+// approaches gain times the input exponentially with the time constant.
+type FirstOrder struct {
+	gain         float64
+	timeConstant float64
+	output       float64
+}
+
+// NewFirstOrder constructs a [*FirstOrder] with the given gain and time
+// constant in seconds.
+func NewFirstOrder(gain, timeConstant float64) *FirstOrder {
+	return &FirstOrder{
+		gain:         gain,
+		timeConstant: timeConstant,
+	}
+}
+
+// Step implements [Plant]. The response is exact for an input that is constant
+// during the step.
+func (p *FirstOrder) Step(input float64, delta time.Duration) float64 {
+	decay := math.Exp(-delta.Seconds() / p.timeConstant)
+	p.output = p.gain*input + (p.output-p.gain*input)*decay
+	return p.output
+}
+
+// Output implements [Plant].
+func (p *FirstOrder) Output() float64 {
+	return p.output
+}
+
+// SecondOrder is a second-order process described by its natural frequency and
+// damping ratio, such as a mass-spring-damper. A damping ratio below one
+// results in an oscillating response.
+type SecondOrder struct {
+	gain             float64
+	naturalFrequency float64
+	damping          float64
+	output           float64
+	velocity         float64
+}
+
+// NewSecondOrder constructs a [*SecondOrder] with the given gain, natural
+// frequency in radians per second, and damping ratio.
+func NewSecondOrder(gain, naturalFrequency, damping float64) *SecondOrder {
+	return &SecondOrder{
+		gain:             gain,
+		naturalFrequency: naturalFrequency,
+		damping:          damping,
+	}
+}
+
+// Step implements [Plant]. The step is divided into substeps small enough
+// relative to the natural frequency to keep the integration accurate.
+func (p *SecondOrder) Step(input float64, delta time.Duration) float64 {
+	const substepsPerRadian = 100
+	substeps := max(1, int(math.Ceil(delta.Seconds()*p.naturalFrequency*substepsPerRadian)))
+	dt := delta.Seconds() / float64(substeps)
+	w := p.naturalFrequency
+	for range substeps {
+		// Semi-implicit Euler keeps the oscillation energy stable.
+		acceleration := w*w*(p.gain*input-p.output) - 2.0*p.damping*w*p.velocity
+		p.velocity += dt * acceleration
+		p.output += dt * p.velocity
+	}
+	return p.output
+}
+
+// Output implements [Plant].
+func (p *SecondOrder) Output() float64 {
+	return p.output
+}
+
+// Integrating is a process without self-regulation, such as a queue or a tank:
+// the output changes at a rate proportional to the input.
+type Integrating struct {
+	gain   float64
+	output float64
+}
+
+// NewIntegrating constructs an [*Integrating] with the given gain.
+func NewIntegrating(gain float64) *Integrating {
+	return &Integrating{
+		gain: gain,
+	}
+}
+
+// Step implements [Plant].
+func (p *Integrating) Step(input float64, delta time.Duration) float64 {
+	p.output += p.gain * input * delta.Seconds()
+	return p.output
+}
+
+// Output implements [Plant].
+func (p *Integrating) Output() float64 {
+	return p.output
+}
+
+// DeadTime delays the input by a fixed duration, such as transport delay or
+// the propagation of a configuration change. Before the delay has passed, the
+// output is zero.
+type DeadTime struct {
+	delay   time.Duration
+	elapsed time.Duration
+	inputs  []timedValue
+	output  float64
+}
+
+type timedValue struct {
+	time  time.Duration
+	value float64
+}
+
+// NewDeadTime constructs a [*DeadTime] with the given delay.
+func NewDeadTime(delay time.Duration) *DeadTime {
+	return &DeadTime{
+		delay: delay,
+	}
+}
+
+// Step implements [Plant]. It returns the input delayed to the beginning of
+// the step, such that a subsequent plant receives it for the whole step.
+func (p *DeadTime) Step(input float64, delta time.Duration) float64 {
+	p.inputs = append(p.inputs, timedValue{time: p.elapsed, value: input})
+	delayed := p.elapsed - p.delay
+	p.elapsed += delta
+
+	// Each input applies until the next one, find the one applied at the
+	// delayed time and discard the ones before it.
+	i := 0
+	for i < len(p.inputs) && p.inputs[i].time <= delayed {
+		i++
+	}
+	if i > 0 {
+		p.output = p.inputs[i-1].value
+		p.inputs = p.inputs[i-1:]
+	}
+	return p.output
+}
+
+// Output implements [Plant].
+func (p *DeadTime) Output() float64 {
+	return p.output
+}
+
+// NewFOPDT constructs a first-order-plus-dead-time process with the given
+// gain, time constant in seconds, and dead time in seconds.
+func NewFOPDT(gain, timeConstant, deadTime float64) Plant {
+	return Series(
+		NewDeadTime(time.Duration(deadTime*float64(time.Second))),
+		NewFirstOrder(gain, timeConstant),
+	)
+}
+
+// Saturation is a static nonlinearity which clamps the input to a range, such
+// as an actuator that can only deliver a limited output.
+type Saturation struct {
+	lower  float64
+	upper  float64
+	output float64
+}
+
+// NewSaturation constructs a [*Saturation] with the given bounds.
+func NewSaturation(lower, upper float64) *Saturation {
+	return &Saturation{
+		lower: lower,
+		upper: upper,
+	}
+}
+
+// Step implements [Plant].
+func (p *Saturation) Step(input float64, _ time.Duration) float64 {
+	p.output = min(max(p.lower, input), p.upper)
+	return p.output
+}
+
+// Output implements [Plant].
+func (p *Saturation) Output() float64 {
+	return p.output
+}
+
+// RateLimit is an actuator whose output follows the input at a limited rate,
+// such as a valve or a worker pool that can only scale so fast.
+type RateLimit struct {
+	maxRise float64
+	maxFall float64
+	output  float64
+}
+
+// NewRateLimit constructs a [*RateLimit] with the given maximum rise and fall
+// rates per second, both positive.
+func NewRateLimit(maxRise, maxFall float64) *RateLimit {
+	return &RateLimit{
+		maxRise: maxRise,
+		maxFall: maxFall,
+	}
+}
+
+// Step implements [Plant].
+func (p *RateLimit) Step(input float64, delta time.Duration) float64 {
+	step := delta.Seconds()
+	p.output += min(max(-p.maxFall*step, input-p.output), p.maxRise*step)
+	return p.output
+}
+
+// Output implements [Plant].
+func (p *RateLimit) Output() float64 {
+	return p.output
+}
+
+// Noise adds normally distributed noise to the input, such as measurement
+// noise. The noise is pseudo-random but deterministic for a given seed.
+type Noise struct {
+	stdDev float64
+	rand   *rand.Rand
+	output float64
+}
+
+// NewNoise constructs a [*Noise] with the given standard deviation and seed.
+func NewNoise(stdDev float64, seed uint64) *Noise {
+	return &Noise{
+		stdDev: stdDev,
+		rand:   rand.New(rand.NewPCG(seed, seed)),
+	}
+}
+
+// Step implements [Plant].
+func (p *Noise) Step(input float64, _ time.Duration) float64 {
+	p.output = input + p.stdDev*p.rand.NormFloat64()
+	return p.output
+}
+
+// Output implements [Plant].
+func (p *Noise) Output() float64 {
+	return p.output
+}
+
+// Disturbance adds a signal to the input, such as a load change or an offset.
+// Placed before a process it acts as a load disturbance, placed after a
+// process it acts as an output disturbance.
+type Disturbance struct {
+	signal  Signal
+	elapsed time.Duration
+	input   float64
+}
+
+// NewDisturbance constructs a [*Disturbance] adding the given signal.
+func NewDisturbance(signal Signal) *Disturbance {
+	return &Disturbance{
+		signal: signal,
+	}
+}
+
+// Step implements [Plant].
+func (p *Disturbance) Step(input float64, delta time.Duration) float64 {
+	p.input = input
+	p.elapsed += delta
+	return p.Output()
+}
+
+// Output implements [Plant].
+func (p *Disturbance) Output() float64 {
+	return p.input + p.signal(p.elapsed)
+}
@@ -0,0 +1,128 @@
+// Package sim provides composable plant models and a deterministic stepper to
+// simulate control loops in virtual time, for example to test the tuning of a
+// [pid.Controller] without time.Sleep.
+package sim
+
+import (
+	"time"
+)
+
+// Plant is a simulated process driven by a control signal.
+type Plant interface {
+	// Step advances the plant by delta while the input is applied and returns
+	// the resulting output.
+	Step(input float64, delta time.Duration) float64
+	// Output returns the output of the plant after the last step.
+	Output() float64
+}
+
+// Controller computes a control signal, it is implemented by
+// [pid.Controller] and any other controller with the same signature.
+type Controller interface {
+	Update(target, current float64, delta time.Duration) float64
+}
+
+// Signal is a value that changes over virtual time, such as the target of a
+// control loop or a disturbance.
+type Signal func(t time.Duration) float64
+
+// Constant returns a [Signal] with a constant value.
+func Constant(value float64) Signal {
+	return func(time.Duration) float64 {
+		return value
+	}
+}
+
+// StepAt returns a [Signal] that changes from before to after at the given
+// time.
+func StepAt(at time.Duration, before, after float64) Signal {
+	return func(t time.Duration) float64 {
+		if t < at {
+			return before
+		}
+		return after
+	}
+}
+
+// series is a chain of plants where the output of each plant is the input of
+// the next one.
+type series []Plant
+
+// Series composes the plants such that the output of each plant is the input
+// of the next one, for example an actuator, followed by a dead time, followed
+// by the process.
+func Series(plants ...Plant) Plant {
+	return series(plants)
+}
+
+func (s series) Step(input float64, delta time.Duration) float64 {
+	for _, p := range s {
+		input = p.Step(input, delta)
+	}
+	return input
+}
+
+func (s series) Output() float64 {
+	if len(s) == 0 {
+		return 0.0
+	}
+	return s[len(s)-1].Output()
+}
+
+// Sample is the state of a control loop at a point in virtual time.
+type Sample struct {
+	Time          time.Duration
+	Target        float64
+	Current       float64
+	ControlSignal float64
+}
+
+// Trajectory is the sequence of samples of a simulated or recorded control
+// loop.
+type Trajectory []Sample
+
+// Stepper runs a control loop in virtual time: in every step the controller
+// computes the control signal from the target and the current output of the
+// plant, which is then applied to the plant for the duration of the step.
+// Given deterministic plants, the trajectory is reproducible.
+type Stepper struct {
+	controller Controller
+	plant      Plant
+	target     Signal
+	step       time.Duration
+	elapsed    time.Duration
+}
+
+// NewStepper constructs a [*Stepper] which advances by the given step.
+func NewStepper(controller Controller, plant Plant, target Signal, step time.Duration) *Stepper {
+	return &Stepper{
+		controller: controller,
+		plant:      plant,
+		target:     target,
+		step:       step,
+	}
+}
+
+// Next advances the control loop by one step and returns the sample taken at
+// the beginning of the step.
+func (s *Stepper) Next() Sample {
+	sample := Sample{
+		Time:    s.elapsed,
+		Target:  s.target(s.elapsed),
+		Current: s.plant.Output(),
+	}
+	sample.ControlSignal = s.controller.Update(sample.Target, sample.Current, s.step)
+	s.plant.Step(sample.ControlSignal, s.step)
+	s.elapsed += s.step
+	return sample
+}
+
+// Run advances the control loop for the given duration and returns the
+// trajectory.
+func (s *Stepper) Run(duration time.Duration) Trajectory {
+	var trajectory Trajectory
+	for end := s.elapsed + duration; s.elapsed < end; {
+		trajectory = append(trajectory, s.Next())
+	}
+	return trajectory
+}
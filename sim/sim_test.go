@@ -0,0 +1,173 @@
+package sim
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konradreiche/pid"
+)
+
+func TestPlants(t *testing.T) {
+	tests := []struct {
+		name  string
+		plant Plant
+		input float64
+		steps int
+		delta time.Duration
+		want  float64
+	}{
+		{
+			name:  "first-order-reaches-63-percent-after-time-constant",
+			plant: NewFirstOrder(2, 5),
+			input: 1,
+			steps: 50,
+			delta: 100 * time.Millisecond,
+			want:  2 * (1 - math.Exp(-1)),
+		},
+		{
+			name:  "second-order-settles-at-gain",
+			plant: NewSecondOrder(2, 1, 0.7),
+			input: 1,
+			steps: 100,
+			delta: 1 * time.Second,
+			want:  2,
+		},
+		{
+			name:  "integrating-accumulates-input",
+			plant: NewIntegrating(0.5),
+			input: 2,
+			steps: 10,
+			delta: 1 * time.Second,
+			want:  10,
+		},
+		{
+			name:  "dead-time-delays-input",
+			plant: NewDeadTime(3 * time.Second),
+			input: 1,
+			steps: 3,
+			delta: 1 * time.Second,
+			want:  0,
+		},
+		{
+			name:  "dead-time-passes-input-after-delay",
+			plant: NewDeadTime(3 * time.Second),
+			input: 1,
+			steps: 4,
+			delta: 1 * time.Second,
+			want:  1,
+		},
+		{
+			name:  "fopdt-responds-after-dead-time",
+			plant: NewFOPDT(2, 5, 1),
+			input: 1,
+			steps: 60,
+			delta: 100 * time.Millisecond,
+			want:  2 * (1 - math.Exp(-1)),
+		},
+		{
+			name:  "saturation-clamps-input",
+			plant: NewSaturation(0, 10),
+			input: 20,
+			steps: 1,
+			delta: 1 * time.Second,
+			want:  10,
+		},
+		{
+			name:  "rate-limit-bounds-rise",
+			plant: NewRateLimit(2, 1),
+			input: 100,
+			steps: 3,
+			delta: 1 * time.Second,
+			want:  6,
+		},
+		{
+			name:  "disturbance-adds-signal",
+			plant: NewDisturbance(StepAt(2*time.Second, 0, 5)),
+			input: 1,
+			steps: 2,
+			delta: 1 * time.Second,
+			want:  6,
+		},
+		{
+			name:  "series-composes-plants",
+			plant: Series(NewSaturation(0, 1), NewIntegrating(1)),
+			input: 5,
+			steps: 4,
+			delta: 1 * time.Second,
+			want:  4,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got float64
+			for range tt.steps {
+				got = tt.plant.Step(tt.input, tt.delta)
+			}
+			if math.Abs(got-tt.want) > 1e-3 {
+				t.Errorf("got %v, want: %v", got, tt.want)
+			}
+			if output := tt.plant.Output(); output != got {
+				t.Errorf("got output %v, want: %v", output, got)
+			}
+		})
+	}
+}
+
+func TestNoise(t *testing.T) {
+	a, b := NewNoise(1, 42), NewNoise(1, 42)
+	var sum, sumSquares float64
+	const n = 10000
+	for range n {
+		got := a.Step(5, time.Second)
+		if want := b.Step(5, time.Second); got != want {
+			t.Fatalf("noise must be deterministic, got %v, want: %v", got, want)
+		}
+		sum += got - 5
+		sumSquares += (got - 5) * (got - 5)
+	}
+	if mean := sum / n; math.Abs(mean) > 0.05 {
+		t.Errorf("got mean %v, want: 0", mean)
+	}
+	if stdDev := math.Sqrt(sumSquares / n); math.Abs(stdDev-1) > 0.05 {
+		t.Errorf("got standard deviation %v, want: 1", stdDev)
+	}
+}
+
+func TestStepper(t *testing.T) {
+	run := func() Trajectory {
+		controller, err := pid.New(
+			pid.WithProportionalGain(2.0),
+			pid.WithIntegralGain(0.5),
+			pid.WithOutputLimit(0, 10),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		plant := Series(
+			NewFOPDT(1, 5, 1),
+			NewNoise(0.01, 1),
+			NewDisturbance(Constant(20)),
+		)
+		return NewStepper(controller, plant, Constant(25), 100*time.Millisecond).Run(2 * time.Minute)
+	}
+
+	trajectory := run()
+	if got, want := len(trajectory), 1200; got != want {
+		t.Fatalf("got %d samples, want: %d", got, want)
+	}
+	if got, want := trajectory[0].Current, 20.0; got != want {
+		t.Errorf("got initial current %v, want: %v", got, want)
+	}
+	if got, want := trajectory[len(trajectory)-1].Time, 2*time.Minute-100*time.Millisecond; got != want {
+		t.Errorf("got final time %v, want: %v", got, want)
+	}
+	if got := trajectory[len(trajectory)-1].Current; math.Abs(got-25) > 0.1 {
+		t.Errorf("got final current %v, want: 25", got)
+	}
+	if diff := cmp.Diff(trajectory, run()); diff != "" {
+		t.Errorf("simulation must be deterministic, diff: %s", diff)
+	}
+}
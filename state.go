@@ -0,0 +1,161 @@
+package pid
+
+import (
+	"encoding"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// stateVersion is the current version of [State]. It must be incremented
+// whenever fields are added to or removed from [State].
+const stateVersion = 2
+
+var (
+	// ErrStateVersion is returned when a [State] was saved with a version that
+	// is not supported.
+	ErrStateVersion = errors.New("unsupported state version")
+	// ErrStateGains is returned when a [State] was saved by a controller with
+	// different gains than the one it is restored to.
+	ErrStateGains = errors.New("state gains do not match controller gains")
+)
+
+var (
+	_ encoding.BinaryMarshaler   = State{}
+	_ encoding.BinaryUnmarshaler = (*State)(nil)
+)
+
+// State is a snapshot of the internal state of a [*Controller]. It can be
+// persisted and restored to resume control after a process restart without
+// having to converge again.
+//
+// The gains are included to validate that the state is restored to a
+// controller with the same configuration, the integral is only meaningful in
+// combination with the integral gain it was accumulated with.
+type State struct {
+	Version          int     `json:"version"`
+	ProportionalGain float64 `json:"proportional_gain"`
+	IntegralGain     float64 `json:"integral_gain"`
+	DerivativeGain   float64 `json:"derivative_gain"`
+	Integral         float64 `json:"integral"`
+	Derivative       float64 `json:"derivative"`
+	PrevControlError float64 `json:"prev_control_error"`
+	PrevMeasurement  float64 `json:"prev_measurement"`
+	PrevTarget       float64 `json:"prev_target"`
+	PrevOutput       float64 `json:"prev_output"`
+}
+
+// stateBinary is the fixed-size binary encoding of [State].
+type stateBinary struct {
+	Version          uint16
+	ProportionalGain float64
+	IntegralGain     float64
+	DerivativeGain   float64
+	Integral         float64
+	Derivative       float64
+	PrevControlError float64
+	PrevMeasurement  float64
+	PrevTarget       float64
+	PrevOutput       float64
+}
+
+// MarshalBinary implements [encoding.BinaryMarshaler].
+func (s State) MarshalBinary() ([]byte, error) {
+	return binary.Append(nil, binary.BigEndian, stateBinary{
+		Version:          uint16(s.Version),
+		ProportionalGain: s.ProportionalGain,
+		IntegralGain:     s.IntegralGain,
+		DerivativeGain:   s.DerivativeGain,
+		Integral:         s.Integral,
+		Derivative:       s.Derivative,
+		PrevControlError: s.PrevControlError,
+		PrevMeasurement:  s.PrevMeasurement,
+		PrevTarget:       s.PrevTarget,
+		PrevOutput:       s.PrevOutput,
+	})
+}
+
+// UnmarshalBinary implements [encoding.BinaryUnmarshaler].
+func (s *State) UnmarshalBinary(data []byte) error {
+	var version uint16
+	if _, err := binary.Decode(data, binary.BigEndian, &version); err != nil {
+		return fmt.Errorf("unmarshal state: %w", err)
+	}
+	if version != stateVersion {
+		return fmt.Errorf("unmarshal state: %w: %d", ErrStateVersion, version)
+	}
+	var b stateBinary
+	n, err := binary.Decode(data, binary.BigEndian, &b)
+	if err != nil {
+		return fmt.Errorf("unmarshal state: %w", err)
+	}
+	if n != len(data) {
+		return fmt.Errorf("unmarshal state: got %d bytes, want: %d", len(data), n)
+	}
+	*s = State{
+		Version:          int(b.Version),
+		ProportionalGain: b.ProportionalGain,
+		IntegralGain:     b.IntegralGain,
+		DerivativeGain:   b.DerivativeGain,
+		Integral:         b.Integral,
+		Derivative:       b.Derivative,
+		PrevControlError: b.PrevControlError,
+		PrevMeasurement:  b.PrevMeasurement,
+		PrevTarget:       b.PrevTarget,
+		PrevOutput:       b.PrevOutput,
+	}
+	return nil
+}
+
+// Snapshot returns the current [State] of the [*Controller].
+func (c *Controller) Snapshot() State {
+	return State{
+		Version:          stateVersion,
+		ProportionalGain: c.proportionalGain,
+		IntegralGain:     c.integralGain,
+		DerivativeGain:   c.derivativeGain,
+		Integral:         c.integral,
+		Derivative:       c.derivative,
+		PrevControlError: c.prevControlError,
+		PrevMeasurement:  c.prevMeasurement,
+		PrevTarget:       c.prevTarget,
+		PrevOutput:       c.prevOutput,
+	}
+}
+
+// Restore replaces the state of the [*Controller] with the given [State]. It
+// returns an error if the state version is unsupported or if the state was
+// saved by a controller with different gains. A controller with a gain
+// schedule changes its gains on every update, it adopts the gains of the state
+// instead until the next update schedules them.
+func (c *Controller) Restore(s State) error {
+	if s.Version != stateVersion {
+		return fmt.Errorf("restore: %w: %d", ErrStateVersion, s.Version)
+	}
+	if c.gainSchedule != nil {
+		// The integral of the state is relative to its gains, configure them
+		// before restoring it.
+		cfg := c.options()
+		cfg.proportionalGain = s.ProportionalGain
+		cfg.integralGain = s.IntegralGain
+		cfg.derivativeGain = s.DerivativeGain
+		c.configure(cfg)
+	} else if s.ProportionalGain != c.proportionalGain ||
+		s.IntegralGain != c.integralGain ||
+		s.DerivativeGain != c.derivativeGain {
+		return fmt.Errorf(
+			"restore: %w: got (%v, %v, %v), want: (%v, %v, %v)",
+			ErrStateGains,
+			s.ProportionalGain, s.IntegralGain, s.DerivativeGain,
+			c.proportionalGain, c.integralGain, c.derivativeGain,
+		)
+	}
+	c.integral = s.Integral
+	c.derivative = s.Derivative
+	c.prevControlError = s.PrevControlError
+	c.prevMeasurement = s.PrevMeasurement
+	c.measured = c.derivativeOnMeasurement
+	c.prevTarget = s.PrevTarget
+	c.prevOutput = s.PrevOutput
+	return nil
+}
@@ -0,0 +1,175 @@
+package pid
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestController_SnapshotRestore(t *testing.T) {
+	opts := []Option{
+		WithProportionalGain(2.0),
+		WithIntegralGain(1.0),
+		WithDerivativeGain(0.5),
+	}
+	a, err := New(opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := New(opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.Update(10, 7, 1*time.Second)
+	a.Update(10, 8, 1*time.Second)
+
+	if err := b.Restore(a.Snapshot()); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(a, b, cmp.AllowUnexported(Controller{}, limit{})); diff != "" {
+		t.Errorf("diff: %s", diff)
+	}
+	if got, want := b.Update(10, 9, 1*time.Second), a.Update(10, 9, 1*time.Second); got != want {
+		t.Errorf("got %v, want: %v", got, want)
+	}
+}
+
+func TestController_SnapshotRestoreGainSchedule(t *testing.T) {
+	opts := []Option{
+		WithGainSchedule(
+			ScheduleOnCurrent,
+			Breakpoint{Value: 0, ProportionalGain: 1, IntegralGain: 1},
+			Breakpoint{Value: 10, ProportionalGain: 2, IntegralGain: 2},
+		),
+	}
+	a, err := New(opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := New(opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.Update(10, 5, 1*time.Second)
+
+	if err := b.Restore(a.Snapshot()); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := b.Update(10, 8, 1*time.Second), a.Update(10, 8, 1*time.Second); got != want {
+		t.Errorf("got %v, want: %v", got, want)
+	}
+}
+
+func TestController_RestoreInvalidState(t *testing.T) {
+	tests := []struct {
+		name    string
+		state   func(s State) State
+		wantErr error
+	}{
+		{
+			name: "unsupported-version",
+			state: func(s State) State {
+				s.Version = stateVersion + 1
+				return s
+			},
+			wantErr: ErrStateVersion,
+		},
+		{
+			name: "different-gains",
+			state: func(s State) State {
+				s.IntegralGain = 2.0
+				return s
+			},
+			wantErr: ErrStateGains,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pid, err := New(WithIntegralGain(1.0))
+			if err != nil {
+				t.Fatal(err)
+			}
+			pid.Update(10, 7, 1*time.Second)
+			want := pid.Snapshot()
+
+			err = pid.Restore(tt.state(want))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got %v, want: %v", err, tt.wantErr)
+			}
+			if diff := cmp.Diff(pid.Snapshot(), want); diff != "" {
+				t.Errorf("state must not change on error, diff: %s", diff)
+			}
+		})
+	}
+}
+
+func TestState_Encoding(t *testing.T) {
+	want := State{
+		Version:          stateVersion,
+		ProportionalGain: 2.0,
+		IntegralGain:     1.0,
+		DerivativeGain:   0.5,
+		Integral:         5,
+		Derivative:       -1,
+		PrevControlError: 2,
+		PrevMeasurement:  8,
+		PrevTarget:       10,
+		PrevOutput:       8.5,
+	}
+
+	t.Run("json", func(t *testing.T) {
+		data, err := json.Marshal(want)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got State
+		if err := json.Unmarshal(data, &got); err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(got, want); diff != "" {
+			t.Errorf("diff: %s", diff)
+		}
+	})
+
+	t.Run("binary", func(t *testing.T) {
+		data, err := want.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got State
+		if err := got.UnmarshalBinary(data); err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(got, want); diff != "" {
+			t.Errorf("diff: %s", diff)
+		}
+	})
+
+	t.Run("binary-unsupported-version", func(t *testing.T) {
+		s := want
+		s.Version = stateVersion + 1
+		data, err := s.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got State
+		if err := got.UnmarshalBinary(data); !errors.Is(err, ErrStateVersion) {
+			t.Fatalf("got %v, want: %v", err, ErrStateVersion)
+		}
+	})
+
+	t.Run("binary-truncated", func(t *testing.T) {
+		data, err := want.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got State
+		if err := got.UnmarshalBinary(data[:len(data)-1]); err == nil {
+			t.Fatal("expected error")
+		}
+	})
+}
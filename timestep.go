@@ -0,0 +1,57 @@
+package pid
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+var (
+	// ErrInvalidTimeStep is returned when an update is rejected because the
+	// time step is zero or negative, for example due to a clock adjustment.
+	ErrInvalidTimeStep = errors.New("time step must be positive")
+	// ErrInvalidInput is returned when an update is rejected because the
+	// target, the measurement, or the feedforward term is NaN or infinite.
+	ErrInvalidInput = errors.New("input must be finite")
+)
+
+// validateUpdate returns an error if the update must be rejected. Accepting it
+// would corrupt the integral and derivative: a zero time step divides by zero,
+// a negative one inverts the integration, and a NaN never leaves the integral
+// again.
+func validateUpdate(target, current, feedforward float64, delta time.Duration) error {
+	if delta <= 0 {
+		return fmt.Errorf("update: %w, got: %v", ErrInvalidTimeStep, delta)
+	}
+	for _, value := range []float64{target, current, feedforward} {
+		if math.IsNaN(value) || math.IsInf(value, 0) {
+			return fmt.Errorf("update: %w, got: %v", ErrInvalidInput, value)
+		}
+	}
+	return nil
+}
+
+// capTimeStep limits the time step to the maximum time step, if any, and
+// returns it in seconds.
+func capTimeStep(delta, maxTimeStep time.Duration) float64 {
+	if maxTimeStep > 0 {
+		delta = min(delta, maxTimeStep)
+	}
+	return delta.Seconds()
+}
+
+// WithMaxTimeStep caps the time step of an update. After a pause, such as a
+// suspended process or a stalled control loop, the time step passed to the
+// next update can be far longer than the interval the controller was tuned
+// for, and integrating the error over it causes a large jump of the control
+// signal. By default, the time step is not capped.
+func WithMaxTimeStep(maxTimeStep time.Duration) Option {
+	return func(o *options) error {
+		if maxTimeStep <= 0 {
+			return fmt.Errorf("max time step: must be positive, got: %v", maxTimeStep)
+		}
+		o.maxTimeStep = maxTimeStep
+		return nil
+	}
+}
@@ -0,0 +1,166 @@
+package pid
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestController_RejectedUpdates(t *testing.T) {
+	tests := []struct {
+		name    string
+		target  float64
+		current float64
+		delta   time.Duration
+		wantErr error
+	}{
+		{
+			name:    "zero-time-step",
+			target:  10,
+			current: 5,
+			delta:   0,
+			wantErr: ErrInvalidTimeStep,
+		},
+		{
+			name:    "negative-time-step",
+			target:  10,
+			current: 5,
+			delta:   -1 * time.Second,
+			wantErr: ErrInvalidTimeStep,
+		},
+		{
+			name:    "nan-target",
+			target:  math.NaN(),
+			current: 5,
+			delta:   1 * time.Second,
+			wantErr: ErrInvalidInput,
+		},
+		{
+			name:    "infinite-measurement",
+			target:  10,
+			current: math.Inf(-1),
+			delta:   1 * time.Second,
+			wantErr: ErrInvalidInput,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pid, err := New(
+				WithProportionalGain(1.0),
+				WithIntegralGain(0.5),
+				WithDerivativeGain(0.25),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := pid.Update(10, 7, 1*time.Second)
+			state := pid.Snapshot()
+
+			got, err := pid.UpdateE(tt.target, tt.current, tt.delta)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got %v, want: %v", err, tt.wantErr)
+			}
+			if got != want {
+				t.Errorf("got %v, want: %v", got, want)
+			}
+			if got := pid.Update(tt.target, tt.current, tt.delta); got != want {
+				t.Errorf("got %v, want: %v", got, want)
+			}
+			if diff := cmp.Diff(pid.Snapshot(), state); diff != "" {
+				t.Errorf("state must not change on rejected update, diff: %s", diff)
+			}
+		})
+	}
+}
+
+func TestController_RejectedFirstUpdateWithinOutputLimit(t *testing.T) {
+	pid, err := New(WithOutputLimit(5, 500))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := pid.Update(100, 50, 0), 5.0; got != want {
+		t.Errorf("got %v, want: %v", got, want)
+	}
+}
+
+func TestController_RejectedUpdateInManualMode(t *testing.T) {
+	pid, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pid.Update(10, 7, 1*time.Second)
+	pid.SetManual(4)
+	if got, want := pid.Update(10, 7, 0), 4.0; got != want {
+		t.Errorf("got %v, want: %v", got, want)
+	}
+}
+
+func TestWithMaxTimeStep(t *testing.T) {
+	pid, err := New(
+		WithProportionalGain(0.0),
+		WithIntegralGain(1.0),
+		WithMaxTimeStep(2*time.Second),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The error is only integrated over the maximum time step after a pause.
+	if got, want := pid.Update(10, 7, 1*time.Hour), 6.0; got != want {
+		t.Errorf("got %v, want: %v", got, want)
+	}
+	if got, want := pid.Update(10, 7, 1*time.Second), 9.0; got != want {
+		t.Errorf("got %v, want: %v", got, want)
+	}
+
+	if _, err := New(WithMaxTimeStep(0)); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestMetrics_RejectedUpdates(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	pid, err := New(WithPrometheusMetrics(t.Name(), registry))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pid.Update(10, 7, 1*time.Second)
+	pid.Update(10, 7, 0)
+	pid.Update(10, 7, -1*time.Second)
+	pid.Update(math.NaN(), 7, 1*time.Second)
+
+	rejected := pid.metrics.(*metrics).rejectedTotal
+	if got, want := testutil.ToFloat64(rejected.WithLabelValues(t.Name(), "invalid_time_step")), 2.0; got != want {
+		t.Errorf("got %v, want: %v", got, want)
+	}
+	if got, want := testutil.ToFloat64(rejected.WithLabelValues(t.Name(), "invalid_input")), 1.0; got != want {
+		t.Errorf("got %v, want: %v", got, want)
+	}
+	if got, want := testutil.ToFloat64(pid.metrics.(*metrics).updatesTotal), 1.0; got != want {
+		t.Errorf("got updates %v, want: %v", got, want)
+	}
+}
+
+func TestVelocityController_RejectedUpdates(t *testing.T) {
+	velocity, err := NewVelocity(WithIntegralGain(1.0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	velocity.Update(10, 7, 1*time.Second)
+	want := velocity.Output()
+
+	if got := velocity.Update(10, 7, 0); got != 0.0 {
+		t.Errorf("got increment %v, want: 0", got)
+	}
+	if got := velocity.Update(10, math.NaN(), 1*time.Second); got != 0.0 {
+		t.Errorf("got increment %v, want: 0", got)
+	}
+	if got := velocity.Output(); got != want {
+		t.Errorf("got output %v, want: %v", got, want)
+	}
+}
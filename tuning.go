@@ -0,0 +1,188 @@
+package pid
+
+import (
+	"errors"
+	"fmt"
+)
+
+// WithTyreusLuybenMethod configures gains using the Tyreus-Luyben tuning
+// method based on the supplied ultimate gain and oscillation period. Compared
+// to [WithZieglerNicholsMethod] it is more conservative, reducing oscillation
+// and improving robustness at the cost of a slower response.
+func WithTyreusLuybenMethod(
+	ultimateGain float64,
+	oscillationPeriod float64,
+) Option {
+	return WithStandardForm(
+		ultimateGain/2.2,
+		2.2*oscillationPeriod,
+		oscillationPeriod/6.3,
+	)
+}
+
+// WithPessenMethod configures gains using Pessen's integral rule based on the
+// supplied ultimate gain and oscillation period. It is more aggressive than
+// [WithZieglerNicholsMethod], improving disturbance rejection.
+func WithPessenMethod(
+	ultimateGain float64,
+	oscillationPeriod float64,
+) Option {
+	return WithStandardForm(
+		0.7*ultimateGain,
+		0.4*oscillationPeriod,
+		0.15*oscillationPeriod,
+	)
+}
+
+// WithNoOvershootMethod configures gains using the no overshoot variant of
+// the Ziegler-Nichols tuning method based on the supplied ultimate gain and
+// oscillation period.
+func WithNoOvershootMethod(
+	ultimateGain float64,
+	oscillationPeriod float64,
+) Option {
+	return WithStandardForm(
+		0.2*ultimateGain,
+		oscillationPeriod/2.0,
+		oscillationPeriod/3.0,
+	)
+}
+
+// FOPDT is a first-order-plus-dead-time model of a process, the basis of most
+// model-based tuning methods. The response to a step in the control signal
+// starts after the dead time (𝐿) and approaches gain (𝐾) times the step
+// exponentially with the time constant (𝑇).
+type FOPDT struct {
+	// Gain (𝐾) is the steady-state change of the measurement per unit change
+	// of the control signal.
+	Gain float64
+	// TimeConstant (𝑇) in seconds is the time it takes for the measurement to
+	// reach 63.2% of its steady-state change, after the dead time.
+	TimeConstant float64
+	// DeadTime (𝐿) in seconds is the delay before the measurement responds to
+	// a change of the control signal.
+	DeadTime float64
+}
+
+// validate checks that the model describes a stable process.
+func (m FOPDT) validate() error {
+	if m.Gain == 0.0 {
+		return errors.New("fopdt: gain must not be zero")
+	}
+	if m.TimeConstant <= 0.0 {
+		return fmt.Errorf("fopdt: time constant must be positive, got: %v", m.TimeConstant)
+	}
+	if m.DeadTime < 0.0 {
+		return fmt.Errorf("fopdt: dead time must not be negative, got: %v", m.DeadTime)
+	}
+	return nil
+}
+
+// withModel returns an [Option] that validates the model before configuring
+// the controller in standard form with the computed parameters.
+func withModel(model FOPDT, requireDeadTime bool, form func() (float64, float64, float64)) Option {
+	return func(o *options) error {
+		if err := model.validate(); err != nil {
+			return err
+		}
+		if requireDeadTime && model.DeadTime == 0.0 {
+			return errors.New("fopdt: dead time must be positive")
+		}
+		return WithStandardForm(form())(o)
+	}
+}
+
+// WithCohenCoonMethod configures gains using the Cohen-Coon tuning method
+// based on the supplied process model. It targets a quarter amplitude decay
+// and is suited for processes with a dead time that is small relative to the
+// time constant.
+func WithCohenCoonMethod(model FOPDT) Option {
+	return withModel(model, true, func() (float64, float64, float64) {
+		k, t, l := model.Gain, model.TimeConstant, model.DeadTime
+		r := l / t
+		return (t / (k * l)) * (4.0/3.0 + r/4.0),
+			l * (32.0 + 6.0*r) / (13.0 + 8.0*r),
+			4.0 * l / (11.0 + 2.0*r)
+	})
+}
+
+// CHRCriterion selects the design criterion of the Chien-Hrones-Reswick
+// tuning method.
+type CHRCriterion int
+
+const (
+	// CHRSetpointNoOvershoot optimizes the response to target changes
+	// without overshoot.
+	CHRSetpointNoOvershoot CHRCriterion = iota
+	// CHRSetpointOvershoot optimizes the response to target changes with 20%
+	// overshoot.
+	CHRSetpointOvershoot
+	// CHRDisturbanceNoOvershoot optimizes the rejection of disturbances
+	// without overshoot.
+	CHRDisturbanceNoOvershoot
+	// CHRDisturbanceOvershoot optimizes the rejection of disturbances with 20%
+	// overshoot.
+	CHRDisturbanceOvershoot
+)
+
+// WithChienHronesReswickMethod configures gains using the Chien-Hrones-Reswick
+// tuning method based on the supplied process model and design criterion.
+func WithChienHronesReswickMethod(model FOPDT, criterion CHRCriterion) Option {
+	return func(o *options) error {
+		// The proportional gain is scaled by the inverse of 𝐾𝐿/𝑇, the integral
+		// and derivative time constants by either 𝑇 or 𝐿.
+		var kp, ti, td float64
+		switch criterion {
+		case CHRSetpointNoOvershoot:
+			kp, ti, td = 0.6, 1.0*model.TimeConstant, 0.5*model.DeadTime
+		case CHRSetpointOvershoot:
+			kp, ti, td = 0.95, 1.4*model.TimeConstant, 0.47*model.DeadTime
+		case CHRDisturbanceNoOvershoot:
+			kp, ti, td = 0.95, 2.4*model.DeadTime, 0.42*model.DeadTime
+		case CHRDisturbanceOvershoot:
+			kp, ti, td = 1.2, 2.0*model.DeadTime, 0.42*model.DeadTime
+		default:
+			return fmt.Errorf("chien-hrones-reswick: unknown criterion: %d", criterion)
+		}
+		return withModel(model, true, func() (float64, float64, float64) {
+			return kp * model.TimeConstant / (model.Gain * model.DeadTime), ti, td
+		})(o)
+	}
+}
+
+// WithIMCMethod configures gains using internal model control (IMC) tuning,
+// also known as lambda tuning, based on the supplied process model. The
+// closed-loop time constant (𝜆) in seconds specifies the desired speed of the
+// response, a larger value results in a slower but more robust response.
+func WithIMCMethod(model FOPDT, closedLoopTimeConstant float64) Option {
+	return func(o *options) error {
+		if closedLoopTimeConstant <= 0.0 {
+			return fmt.Errorf("imc: closed-loop time constant must be positive, got: %v", closedLoopTimeConstant)
+		}
+		return withModel(model, false, func() (float64, float64, float64) {
+			k, t, l := model.Gain, model.TimeConstant, model.DeadTime
+			return (2.0*t + l) / (k * (2.0*closedLoopTimeConstant + l)),
+				t + l/2.0,
+				t * l / (2.0*t + l)
+		})(o)
+	}
+}
+
+// WithSIMCMethod configures gains using Skogestad's SIMC tuning method based
+// on the supplied process model, resulting in a PI controller. The closed-loop
+// time constant (𝜏𝑐) in seconds specifies the desired speed of the response,
+// choosing it equal to the dead time is recommended for a good trade-off
+// between performance and robustness.
+func WithSIMCMethod(model FOPDT, closedLoopTimeConstant float64) Option {
+	return func(o *options) error {
+		if closedLoopTimeConstant < 0.0 || closedLoopTimeConstant+model.DeadTime <= 0.0 {
+			return fmt.Errorf("simc: closed-loop time constant must be positive, got: %v", closedLoopTimeConstant)
+		}
+		return withModel(model, false, func() (float64, float64, float64) {
+			k, t, l := model.Gain, model.TimeConstant, model.DeadTime
+			return t / (k * (closedLoopTimeConstant + l)),
+				min(t, 4.0*(closedLoopTimeConstant+l)),
+				0.0
+		})(o)
+	}
+}
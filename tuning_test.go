@@ -0,0 +1,172 @@
+package pid
+
+import (
+	"math"
+	"testing"
+)
+
+func TestTuningRules(t *testing.T) {
+	tests := []struct {
+		name string
+		rule TuningRule
+		want options
+	}{
+		{
+			name: "ziegler-nichols",
+			rule: WithZieglerNicholsMethod,
+			want: options{proportionalGain: 6, integralGain: 6 / 2.0, derivativeGain: 6 * 0.5},
+		},
+		{
+			name: "tyreus-luyben",
+			rule: WithTyreusLuybenMethod,
+			want: options{proportionalGain: 10 / 2.2, integralGain: 10 / 2.2 / 8.8, derivativeGain: 10 / 2.2 * 4 / 6.3},
+		},
+		{
+			name: "pessen",
+			rule: WithPessenMethod,
+			want: options{proportionalGain: 7, integralGain: 7 / 1.6, derivativeGain: 7 * 0.6},
+		},
+		{
+			name: "no-overshoot",
+			rule: WithNoOvershootMethod,
+			want: options{proportionalGain: 2, integralGain: 2 / 2.0, derivativeGain: 2 * 4 / 3.0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got options
+			if err := tt.rule(10, 4)(&got); err != nil {
+				t.Fatal(err)
+			}
+			checkGains(t, got, tt.want, 1e-9)
+		})
+	}
+}
+
+func TestModelTuningRules(t *testing.T) {
+	// Process with 𝐾=1, 𝑇=10s and 𝐿=2s, the normalized dead time 𝐾𝐿/𝑇 is 0.2.
+	// The expected gains are the parameters of the published tables evaluated
+	// for this process and rounded to six decimals:
+	//
+	//   - Cohen and Coon, Theoretical Consideration of Retarded Control, 1953:
+	//     𝐾𝑝=6.916667, 𝑇𝑖=4.547945s, 𝑇𝑑=0.701754s.
+	//   - Chien, Hrones and Reswick, On the Automatic Control of Generalized
+	//     Passive Systems, 1952, PID rows for 0% and 20% overshoot.
+	//   - Rivera, Morari and Skogestad, Internal Model Control: PID Controller
+	//     Design, 1986, PID row for the FOPDT model with 𝜆=2s:
+	//     𝐾𝑝=3.666667, 𝑇𝑖=11s, 𝑇𝑑=0.909091s.
+	//   - Skogestad, Simple Analytic Rules for Model Reduction and PID
+	//     Controller Tuning, 2003, SIMC PI rule with 𝜏𝑐=𝐿.
+	model := FOPDT{Gain: 1, TimeConstant: 10, DeadTime: 2}
+
+	tests := []struct {
+		name string
+		opt  Option
+		want options
+	}{
+		{
+			name: "cohen-coon",
+			opt:  WithCohenCoonMethod(model),
+			want: options{proportionalGain: 6.916667, integralGain: 1.520833, derivativeGain: 4.853801},
+		},
+		{
+			name: "chien-hrones-reswick-setpoint-no-overshoot",
+			opt:  WithChienHronesReswickMethod(model, CHRSetpointNoOvershoot),
+			want: options{proportionalGain: 3, integralGain: 0.3, derivativeGain: 3},
+		},
+		{
+			name: "chien-hrones-reswick-setpoint-overshoot",
+			opt:  WithChienHronesReswickMethod(model, CHRSetpointOvershoot),
+			want: options{proportionalGain: 4.75, integralGain: 0.339286, derivativeGain: 4.465},
+		},
+		{
+			name: "chien-hrones-reswick-disturbance-no-overshoot",
+			opt:  WithChienHronesReswickMethod(model, CHRDisturbanceNoOvershoot),
+			want: options{proportionalGain: 4.75, integralGain: 0.989583, derivativeGain: 3.99},
+		},
+		{
+			name: "chien-hrones-reswick-disturbance-overshoot",
+			opt:  WithChienHronesReswickMethod(model, CHRDisturbanceOvershoot),
+			want: options{proportionalGain: 6, integralGain: 1.5, derivativeGain: 5.04},
+		},
+		{
+			name: "imc",
+			opt:  WithIMCMethod(model, 2),
+			want: options{proportionalGain: 3.666667, integralGain: 0.333333, derivativeGain: 3.333333},
+		},
+		{
+			name: "simc",
+			opt:  WithSIMCMethod(model, 2),
+			want: options{proportionalGain: 2.5, integralGain: 0.25},
+		},
+		{
+			name: "simc-integral-time-limited-by-closed-loop",
+			opt:  WithSIMCMethod(FOPDT{Gain: 2, TimeConstant: 100, DeadTime: 1}, 1),
+			want: options{proportionalGain: 25, integralGain: 3.125},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got options
+			if err := tt.opt(&got); err != nil {
+				t.Fatal(err)
+			}
+			checkGains(t, got, tt.want, 1e-6)
+		})
+	}
+}
+
+func TestModelTuningRules_InvalidModel(t *testing.T) {
+	tests := []struct {
+		name string
+		opt  Option
+	}{
+		{
+			name: "zero-gain",
+			opt:  WithIMCMethod(FOPDT{Gain: 0, TimeConstant: 10, DeadTime: 2}, 1),
+		},
+		{
+			name: "non-positive-time-constant",
+			opt:  WithSIMCMethod(FOPDT{Gain: 1, TimeConstant: 0, DeadTime: 2}, 1),
+		},
+		{
+			name: "negative-dead-time",
+			opt:  WithIMCMethod(FOPDT{Gain: 1, TimeConstant: 10, DeadTime: -1}, 1),
+		},
+		{
+			name: "cohen-coon-without-dead-time",
+			opt:  WithCohenCoonMethod(FOPDT{Gain: 1, TimeConstant: 10}),
+		},
+		{
+			name: "chien-hrones-reswick-unknown-criterion",
+			opt:  WithChienHronesReswickMethod(FOPDT{Gain: 1, TimeConstant: 10, DeadTime: 2}, CHRCriterion(-1)),
+		},
+		{
+			name: "imc-non-positive-closed-loop-time-constant",
+			opt:  WithIMCMethod(FOPDT{Gain: 1, TimeConstant: 10, DeadTime: 2}, 0),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := New(tt.opt); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
+
+func checkGains(tb testing.TB, got, want options, tolerance float64) {
+	tb.Helper()
+	if math.Abs(got.proportionalGain-want.proportionalGain) > tolerance {
+		tb.Errorf("got proportional gain %v, want: %v", got.proportionalGain, want.proportionalGain)
+	}
+	if math.Abs(got.integralGain-want.integralGain) > tolerance {
+		tb.Errorf("got integral gain %v, want: %v", got.integralGain, want.integralGain)
+	}
+	if math.Abs(got.derivativeGain-want.derivativeGain) > tolerance {
+		tb.Errorf("got derivative gain %v, want: %v", got.derivativeGain, want.derivativeGain)
+	}
+}
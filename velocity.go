@@ -0,0 +1,200 @@
+package pid
+
+import (
+	"errors"
+	"time"
+)
+
+// VelocityController implements the velocity form, also known as incremental
+// form, of a PID controller. Instead of the control signal, each update returns
+// the change of the control signal (Δu) computed from the change of the error.
+// This suits actuators that accept increments, such as adding or removing
+// replicas, rather than an absolute value.
+//
+// The velocity form has no integral state that can wind up: once the control
+// signal reaches the output limit, increments beyond it are discarded. The
+// controller tracks the control signal as the sum of the increments, which can
+// be aligned with the actual actuator position using
+// [VelocityController.SetOutput], making transfers from manual operation
+// bumpless.
+type VelocityController struct {
+	proportionalGain float64
+	integralGain     float64
+	derivativeGain   float64
+
+	proportionalWeight float64
+	derivativeWeight   float64
+
+	errorShaping *errorShaping
+
+	prevControlError      float64
+	prevProportionalError float64
+	prevMeasurement       float64
+	measured              bool
+	prevTarget            float64
+	derivative            float64
+
+	// Sum of the increments, that is, the control signal of the positional
+	// form, used to apply the output limits. It starts at zero clamped to the
+	// output limit.
+	output          float64
+	outputLimit     limit
+	outputRateLimit *limit
+
+	lowPassFilterError      float64
+	lowPassFilterDerivative float64
+	trapezoidalIntegral     bool
+	derivativeOnMeasurement bool
+	maxTimeStep             time.Duration
+
+	metrics recorder
+}
+
+// NewVelocity constructs a [*VelocityController] configured by the provided
+// options, see [New]. Anti-windup options have no effect since the velocity
+// form does not wind up, neither does [WithClock]. Gain schedules are not
+// supported. Metrics record the control signal like for a [*Controller], the
+// increments are recorded as a separate control signal increment gauge.
+func NewVelocity(opts ...Option) (*VelocityController, error) {
+	cfg := defaultOptions()
+	if err := WithOptions(opts...)(&cfg); err != nil {
+		return nil, err
+	}
+	if cfg.gainSchedule != nil {
+		return nil, errors.New("velocity: gain schedule is not supported")
+	}
+	return &VelocityController{
+		proportionalGain:        cfg.proportionalGain,
+		integralGain:            cfg.integralGain,
+		derivativeGain:          cfg.derivativeGain,
+		proportionalWeight:      cfg.proportionalWeight,
+		derivativeWeight:        cfg.derivativeWeight,
+		errorShaping:            cfg.errorShaping,
+		output:                  cfg.outputLimit.apply(0.0),
+		outputLimit:             cfg.outputLimit,
+		outputRateLimit:         cfg.outputRateLimit,
+		lowPassFilterError:      cfg.lowPassFilterError,
+		lowPassFilterDerivative: cfg.lowPassFilterDerivative,
+		trapezoidalIntegral:     cfg.trapezoidalIntegral,
+		derivativeOnMeasurement: cfg.derivativeOnMeasurement,
+		maxTimeStep:             cfg.maxTimeStep,
+		metrics:                 cfg.metrics,
+	}, nil
+}
+
+// Update computes and returns the change of the control signal for the given
+// target and current measurement over the provided time step. Without limits,
+// the sum of the increments equals the control signal of a [*Controller] with
+// the same configuration. A rejected update, see [Controller.Update], returns
+// a zero increment.
+func (v *VelocityController) Update(target, current float64, delta time.Duration) (increment float64) {
+	if err := validateUpdate(target, current, 0.0, delta); err != nil {
+		if v.metrics != nil {
+			v.metrics.reject(err)
+		}
+		return 0.0
+	}
+	step := capTimeStep(delta, v.maxTimeStep)
+
+	defer func() {
+		if v.metrics == nil {
+			return
+		}
+		v.metrics.output(v.output)
+		v.metrics.increment(increment)
+	}()
+	if v.metrics != nil {
+		v.metrics.update(target, current, 0.0, step)
+	}
+
+	controlError := target - current
+	if v.errorShaping != nil {
+		controlError = v.errorShaping.apply(controlError)
+	}
+	if v.lowPassFilterError != 0.0 {
+		controlError = (controlError*step + v.prevControlError*v.lowPassFilterError) / (v.lowPassFilterError + step)
+	}
+
+	// The integral term contributes the area under the error of this step.
+	integral := controlError * step
+	if v.trapezoidalIntegral {
+		integral = step * (controlError + v.prevControlError) / 2.0
+	}
+
+	var change float64
+	if v.derivativeOnMeasurement {
+		if !v.measured {
+			v.prevMeasurement = current
+			v.measured = true
+		}
+		measurement := current
+		if v.lowPassFilterError != 0.0 {
+			measurement = (current*step + v.prevMeasurement*v.lowPassFilterError) / (v.lowPassFilterError + step)
+		}
+		change = v.prevMeasurement - measurement
+		v.prevMeasurement = measurement
+	} else {
+		change = controlError - v.prevControlError + (v.derivativeWeight-1.0)*(target-v.prevTarget)
+	}
+	derivative := change / step
+	if v.lowPassFilterDerivative != 0.0 {
+		derivative = (change + v.lowPassFilterDerivative*v.derivative) / (step + v.lowPassFilterDerivative)
+	}
+
+	proportionalError := controlError + (v.proportionalWeight-1.0)*target
+	increment = v.proportionalGain*(proportionalError-v.prevProportionalError) +
+		v.integralGain*integral +
+		v.derivativeGain*(derivative-v.derivative)
+
+	v.prevControlError = controlError
+	v.prevProportionalError = proportionalError
+	v.prevTarget = target
+	v.derivative = derivative
+
+	// Limiting the control signal discards the excess of the increment, which
+	// is what prevents windup.
+	output := v.output + increment
+	if v.outputRateLimit != nil {
+		output = v.outputRateLimit.applyRate(output, v.output, step)
+	}
+	output = v.outputLimit.apply(output)
+	increment = output - v.output
+	v.output = output
+	return increment
+}
+
+// Output returns the control signal, that is, the sum of the increments
+// returned by [VelocityController.Update] within the output limit.
+func (v *VelocityController) Output() float64 {
+	return v.output
+}
+
+// SetOutput sets the control signal to which subsequent increments are added,
+// clamped to the output limit. Call it to align the controller with the actual
+// actuator position, for example after an operator changed it manually.
+func (v *VelocityController) SetOutput(output float64) {
+	v.output = v.outputLimit.apply(output)
+}
+
+// Reset clears the accumulated state of the [*VelocityController] as if it had
+// just been constructed, including the control signal.
+func (v *VelocityController) Reset() {
+	v.prevControlError = 0.0
+	v.prevProportionalError = 0.0
+	v.prevMeasurement = 0.0
+	v.measured = false
+	v.prevTarget = 0.0
+	v.derivative = 0.0
+	v.output = v.outputLimit.apply(0.0)
+}
+
+// Close removes the metrics series of the [*VelocityController], see
+// [Controller.Close].
+func (v *VelocityController) Close() error {
+	if v.metrics == nil {
+		return nil
+	}
+	v.metrics.close()
+	v.metrics = nil
+	return nil
+}
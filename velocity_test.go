@@ -0,0 +1,208 @@
+package pid
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestVelocityController_MatchesPositionalForm(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []Option
+	}{
+		{
+			name: "pid",
+			opts: []Option{
+				WithProportionalGain(1.5),
+				WithIntegralGain(0.5),
+				WithDerivativeGain(0.25),
+			},
+		},
+		{
+			name: "standard-form-with-filters",
+			opts: []Option{WithStandardForm(2.0, 1.0, 0.25)},
+		},
+		{
+			name: "trapezoidal-integral",
+			opts: []Option{
+				WithIntegralGain(0.5),
+				WithTrapezoidalIntegral(true),
+			},
+		},
+		{
+			name: "derivative-on-measurement",
+			opts: []Option{
+				WithStandardForm(2.0, 1.0, 0.25),
+				WithDerivativeOnMeasurement(true),
+			},
+		},
+		{
+			name: "setpoint-weights",
+			opts: []Option{
+				WithStandardForm(2.0, 1.0, 0.25),
+				WithSetpointWeights(0.5, 0.0),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			positional, err := New(tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			velocity, err := NewVelocity(tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			targets := []float64{10, 10, 10, 20, 20, 20}
+			inputs := []float64{0, 4, 7, 9, 14, 18}
+			var sum float64
+			for i := range targets {
+				want := positional.Update(targets[i], inputs[i], 500*time.Millisecond)
+				sum += velocity.Update(targets[i], inputs[i], 500*time.Millisecond)
+				if math.Abs(sum-want) > 1e-9 {
+					t.Errorf("step %d: got %v, want: %v", i, sum, want)
+				}
+			}
+			if got, want := velocity.Output(), sum; got != want {
+				t.Errorf("got output %v, want: %v", got, want)
+			}
+		})
+	}
+}
+
+func TestVelocityController_DerivativeOnMeasurementFirstUpdate(t *testing.T) {
+	velocity, err := NewVelocity(
+		WithProportionalGain(0.0),
+		WithDerivativeGain(1.0),
+		WithDerivativeOnMeasurement(true),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := velocity.Update(500, 500, 1*time.Second), 0.0; got != want {
+		t.Errorf("got increment %v, want: %v", got, want)
+	}
+}
+
+func TestVelocityController_OutputLimit(t *testing.T) {
+	velocity, err := NewVelocity(
+		WithProportionalGain(0.0),
+		WithIntegralGain(1.0),
+		WithOutputLimit(0, 5),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range 10 {
+		velocity.Update(10, 0, 1*time.Second)
+	}
+	if got, want := velocity.Output(), 5.0; got != want {
+		t.Fatalf("got output %v, want: %v", got, want)
+	}
+
+	// The control signal leaves saturation as soon as the error reverses.
+	if got, want := velocity.Update(10, 11, 1*time.Second), -1.0; got != want {
+		t.Errorf("got increment %v, want: %v", got, want)
+	}
+}
+
+func TestVelocityController_OutputStartsWithinOutputLimit(t *testing.T) {
+	velocity, err := NewVelocity(
+		WithProportionalGain(0.0),
+		WithIntegralGain(1.0),
+		WithOutputLimit(5, 500),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := velocity.Output(), 5.0; got != want {
+		t.Fatalf("got output %v, want: %v", got, want)
+	}
+	// The first increment is added to the lower bound rather than to zero.
+	if got, want := velocity.Update(10, 9, 1*time.Second), 1.0; got != want {
+		t.Errorf("got increment %v, want: %v", got, want)
+	}
+	if got, want := velocity.Output(), 6.0; got != want {
+		t.Errorf("got output %v, want: %v", got, want)
+	}
+
+	velocity.Reset()
+	if got, want := velocity.Output(), 5.0; got != want {
+		t.Errorf("got output %v, want: %v", got, want)
+	}
+}
+
+func TestVelocityController_OutputRateLimit(t *testing.T) {
+	velocity, err := NewVelocity(WithOutputRateLimit(2, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := velocity.Update(10, 0, 1*time.Second), 2.0; got != want {
+		t.Errorf("got increment %v, want: %v", got, want)
+	}
+	if got, want := velocity.Update(-10, 0, 1*time.Second), -1.0; got != want {
+		t.Errorf("got increment %v, want: %v", got, want)
+	}
+}
+
+func TestVelocityController_SetOutput(t *testing.T) {
+	velocity, err := NewVelocity(
+		WithProportionalGain(0.0),
+		WithIntegralGain(1.0),
+		WithOutputLimit(0, 5),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	velocity.SetOutput(10)
+	if got, want := velocity.Output(), 5.0; got != want {
+		t.Fatalf("got output %v, want: %v", got, want)
+	}
+
+	velocity.SetOutput(3)
+	if got, want := velocity.Update(10, 9, 1*time.Second), 1.0; got != want {
+		t.Errorf("got increment %v, want: %v", got, want)
+	}
+	if got, want := velocity.Output(), 4.0; got != want {
+		t.Errorf("got output %v, want: %v", got, want)
+	}
+
+	velocity.Reset()
+	if got, want := velocity.Output(), 0.0; got != want {
+		t.Errorf("got output %v, want: %v", got, want)
+	}
+}
+
+func TestVelocityController_Metrics(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	velocity, err := NewVelocity(WithPrometheusMetrics(t.Name(), registry))
+	if err != nil {
+		t.Fatal(err)
+	}
+	velocity.Update(5, 1, 1*time.Second)
+	velocity.Update(5, 2, 1*time.Second)
+
+	if got, want := testutil.ToFloat64(velocity.metrics.(*metrics).updatesTotal), 2.0; got != want {
+		t.Errorf("got updates %v, want: %v", got, want)
+	}
+	if got, want := testutil.ToFloat64(velocity.metrics.(*metrics).controlSignal), 3.0; got != want {
+		t.Errorf("got control signal %v, want: %v", got, want)
+	}
+	if got, want := testutil.ToFloat64(velocity.metrics.(*metrics).controlSignalIncrement), -1.0; got != want {
+		t.Errorf("got control signal increment %v, want: %v", got, want)
+	}
+}
+
+func TestNewVelocity_GainSchedule(t *testing.T) {
+	_, err := NewVelocity(WithGainSchedule(ScheduleOnCurrent, Breakpoint{IntegralGain: 1}))
+	if err == nil {
+		t.Fatal("expected error")
+	}
+}